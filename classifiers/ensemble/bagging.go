@@ -0,0 +1,132 @@
+package ensemble
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/bsm/reason/classifiers/hoeffding"
+	"github.com/bsm/reason/classifiers/internal/helpers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7747, (*Bagging)(nil))
+}
+
+// Bagging is an online bagging ensemble of Hoeffding trees. Each member
+// is trained on each instance with a weight drawn from a Poisson(1)
+// distribution (see Config.Lambda). See "Online Bagging and Boosting"
+// by Nikunj C. Oza and Stuart Russell (2001).
+type Bagging struct {
+	conf  *Config
+	model *core.Model
+	trees []*hoeffding.Tree
+
+	rnd *rand.Rand
+	mu  sync.Mutex
+}
+
+// NewBagging inits a new ensemble from a model
+func NewBagging(model *core.Model, conf *Config) *Bagging {
+	if conf == nil {
+		conf = new(Config)
+	}
+	conf.norm(1)
+
+	trees := make([]*hoeffding.Tree, conf.Size)
+	for i := range trees {
+		tconf := conf.Tree
+		trees[i] = hoeffding.New(model, &tconf)
+	}
+
+	return &Bagging{
+		conf:  conf,
+		model: model,
+		trees: trees,
+		rnd:   rand.New(rand.NewSource(conf.Seed)),
+	}
+}
+
+// LoadBagging loads an ensemble from a readable source with the given config.
+// Please note that the ensemble size is restored from the source.
+func LoadBagging(r io.Reader, conf *Config) (*Bagging, error) {
+	var b *Bagging
+	if err := msgpack.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+
+	if conf == nil {
+		conf = new(Config)
+	}
+	conf.norm(1)
+	conf.Size = len(b.trees)
+
+	for _, t := range b.trees {
+		tconf := conf.Tree
+		t.SetConfig(&tconf)
+	}
+	b.conf = conf
+	b.rnd = rand.New(rand.NewSource(conf.Seed))
+	return b, nil
+}
+
+// Model returns the model
+func (b *Bagging) Model() *core.Model {
+	return b.model
+}
+
+// Trees returns the ensemble members
+func (b *Bagging) Trees() []*hoeffding.Tree {
+	return b.trees
+}
+
+// Train passes an instance to the ensemble for training purposes
+func (b *Bagging) Train(inst core.Instance) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	weight := inst.GetInstanceWeight()
+	for _, t := range b.trees {
+		if k := poisson(b.rnd, b.conf.Lambda); k > 0 {
+			t.Train(helpers.WeightedInstance{Instance: inst, Weight: weight * float64(k)})
+		}
+	}
+}
+
+// Predict combines the predictions of all members. For classifications,
+// votes are the sum of the members' normalised class distributions.
+// For regressions, the value is the mean of the members' predictions
+// and the variance incorporates the disagreement between them.
+func (b *Bagging) Predict(inst core.Instance) core.Prediction {
+	predictions := make([]core.Prediction, len(b.trees))
+	for i, t := range b.trees {
+		predictions[i] = t.Predict(inst)
+	}
+
+	res := helpers.CombinePredictions(b.model.IsRegression(), predictions, nil)
+	for _, p := range predictions {
+		p.Release()
+	}
+	return res
+}
+
+// DumpTo writes the ensemble to a writer
+func (b *Bagging) DumpTo(w io.Writer) error {
+	enc := msgpack.NewEncoder(w)
+	defer enc.Close()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return enc.Encode(b)
+}
+
+func (b *Bagging) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(b.model, b.trees)
+}
+
+func (b *Bagging) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&b.model, &b.trees)
+}
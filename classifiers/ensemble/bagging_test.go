@@ -0,0 +1,92 @@
+package ensemble
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/bsm/reason/classifiers/hoeffding"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/eval"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bagging", func() {
+	model := core.NewModel(
+		&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+		&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+		&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+	)
+
+	rnd := rand.New(rand.NewSource(1))
+	generate := func(n int) []core.Instance {
+		insts := make([]core.Instance, 0, n)
+		for i := 0; i < n; i++ {
+			x, y := rnd.Float64(), rnd.Float64()
+			class := "a"
+			if x+y+rnd.NormFloat64()*0.1 > 1 {
+				class = "b"
+			}
+			insts = append(insts, core.MapInstance{"x": x, "y": y, "class": class})
+		}
+		return insts
+	}
+	train, test := generate(3000), generate(1000)
+
+	var subject *Bagging
+
+	BeforeEach(func() {
+		subject = NewBagging(model, &Config{Tree: hoeffding.Config{GracePeriod: 50}})
+		for _, inst := range train {
+			subject.Train(inst)
+		}
+	})
+
+	It("should train members", func() {
+		Expect(subject.Trees()).To(HaveLen(10))
+		Expect(subject.Trees()[0].Info()).NotTo(Equal(subject.Trees()[1].Info()))
+	})
+
+	It("should predict", func() {
+		stats := eval.NewClassification(model)
+		for _, inst := range test {
+			stats.Record(inst, subject.Predict(inst))
+		}
+		Expect(stats.Correct()).To(BeNumerically(">", 0.85))
+
+		prediction := subject.Predict(core.MapInstance{"x": 0.1, "y": 0.2})
+		Expect(prediction.Index()).To(Equal(0))
+		Expect(prediction[0].Votes + prediction[1].Votes).To(BeNumerically("~", 10.0, 0.001))
+	})
+
+	It("should predict regressions", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "z", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+		)
+		bagging := NewBagging(model, &Config{Size: 5, Tree: hoeffding.Config{GracePeriod: 50}})
+		for i := 0; i < 3000; i++ {
+			x := rnd.Float64() * 10
+			bagging.Train(core.MapInstance{"x": x, "z": 2*x + rnd.NormFloat64()})
+		}
+
+		prediction := bagging.Predict(core.MapInstance{"x": 5.0})
+		Expect(prediction).To(HaveLen(1))
+		Expect(prediction.Value()).To(BeNumerically("~", 10, 1))
+		Expect(prediction.Top().Variance).To(BeNumerically(">", 0))
+	})
+
+	It("should dump/load", func() {
+		buf := new(bytes.Buffer)
+		Expect(subject.DumpTo(buf)).To(Succeed())
+
+		loaded, err := LoadBagging(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Trees()).To(HaveLen(10))
+		Expect(loaded.Model()).To(Equal(model))
+		for _, inst := range test[:100] {
+			Expect(loaded.Predict(inst)).To(Equal(subject.Predict(inst)))
+		}
+	})
+
+})
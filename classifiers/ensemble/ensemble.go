@@ -0,0 +1,64 @@
+// Package ensemble implements online ensembles of Hoeffding trees.
+package ensemble
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/bsm/reason/classifiers/hoeffding"
+)
+
+// Config configures behaviour
+type Config struct {
+	// The number of ensemble members
+	// Default: 10
+	Size int
+
+	// The seed of the random number generator
+	// Default: 0
+	Seed int64
+
+	// The rate of the Poisson distribution members draw
+	// instance weights from
+	// Default: 1 (bagging) or 6 (forests)
+	Lambda float64
+
+	// The confidence of the ADWIN detectors which monitor the error
+	// rates of forest members. Warnings start background trees, drifts
+	// replace members with their background trees. To disable drift
+	// detection, set DriftConfidence to <0.
+	// Default: 0.01 (warnings) and 0.001 (drifts)
+	WarningConfidence, DriftConfidence float64
+
+	// The configuration of each member tree. For forests, Tree.Subspace
+	// defaults to the square root of the number of predictors, plus one.
+	Tree hoeffding.Config
+}
+
+func (c *Config) norm(lambda float64) {
+	if c.Size <= 0 {
+		c.Size = 10
+	}
+	if c.Lambda <= 0 {
+		c.Lambda = lambda
+	}
+	if c.WarningConfidence <= 0 {
+		c.WarningConfidence = 0.01
+	}
+	if c.DriftConfidence == 0 {
+		c.DriftConfidence = 0.001
+	}
+}
+
+// --------------------------------------------------------------------
+
+// poisson draws a random number from a Poisson distribution
+func poisson(rnd *rand.Rand, lambda float64) int {
+	limit, prod := math.Exp(-lambda), rnd.Float64()
+	n := 0
+	for prod > limit {
+		prod *= rnd.Float64()
+		n++
+	}
+	return n
+}
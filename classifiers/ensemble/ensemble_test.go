@@ -0,0 +1,29 @@
+package ensemble
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("poisson", func() {
+
+	It("should draw from poisson distributions", func() {
+		rnd := rand.New(rand.NewSource(1))
+		sum := 0
+		for i := 0; i < 10000; i++ {
+			sum += poisson(rnd, 1)
+		}
+		Expect(float64(sum) / 10000).To(BeNumerically("~", 1.0, 0.05))
+	})
+
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "classifiers/ensemble")
+}
@@ -0,0 +1,294 @@
+package ensemble
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/bsm/reason/classifiers/hoeffding"
+	"github.com/bsm/reason/classifiers/internal/helpers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
+	"github.com/bsm/reason/eval"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+)
+
+func init() {
+	msgpack.Register(7753, (*Forest)(nil))
+	msgpack.Register(7754, (*forestMember)(nil))
+}
+
+// Forest is an Adaptive Random Forest. Member trees only consider a
+// random subset of predictors at each leaf and are trained with weights
+// drawn from a Poisson(6) distribution. Each member monitors its error
+// rate, starts a background tree when a warning is detected and is
+// replaced by its background tree once a drift is detected. Predictions
+// are weighted by the members' accuracy (classifications) or the inverse
+// of their mean squared error (regressions), measured since they were
+// started. See "Adaptive random forests for evolving data stream
+// classification" by Heitor M. Gomes et al. (2017).
+type Forest struct {
+	conf    *Config
+	model   *core.Model
+	members []*forestMember
+
+	// target tracks the distribution of regression targets,
+	// it is not persisted
+	target util.NumSeries
+
+	rnd *rand.Rand
+	mu  sync.RWMutex
+}
+
+// NewForest inits a new forest from a model
+func NewForest(model *core.Model, conf *Config) *Forest {
+	if conf == nil {
+		conf = new(Config)
+	}
+	conf.norm(6)
+
+	f := &Forest{
+		conf:    conf,
+		model:   model,
+		members: make([]*forestMember, conf.Size),
+		rnd:     rand.New(rand.NewSource(conf.Seed)),
+	}
+	for i := range f.members {
+		f.members[i] = &forestMember{Tree: f.newTree()}
+		f.members[i].reset(f)
+	}
+	return f
+}
+
+// LoadForest loads a forest from a readable source with the given config.
+// Please note that the forest size is restored from the source, while the
+// member performance and target stats are reset.
+func LoadForest(r io.Reader, conf *Config) (*Forest, error) {
+	var f *Forest
+	if err := msgpack.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if conf == nil {
+		conf = new(Config)
+	}
+	conf.norm(6)
+	conf.Size = len(f.members)
+
+	f.conf = conf
+	f.rnd = rand.New(rand.NewSource(conf.Seed))
+	for _, m := range f.members {
+		m.Tree.SetConfig(f.treeConfig())
+		if m.Background != nil {
+			m.Background.SetConfig(f.treeConfig())
+		}
+		m.Stats = f.newStats()
+	}
+	return f, nil
+}
+
+// Model returns the model
+func (f *Forest) Model() *core.Model {
+	return f.model
+}
+
+// Trees returns the (foreground) member trees
+func (f *Forest) Trees() []*hoeffding.Tree {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	trees := make([]*hoeffding.Tree, len(f.members))
+	for i, m := range f.members {
+		trees[i] = m.Tree
+	}
+	return trees
+}
+
+// Train passes an instance to the forest for training purposes
+func (f *Forest) Train(inst core.Instance) {
+	tv := f.model.Target().Value(inst)
+	if tv.IsMissing() {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	weight := inst.GetInstanceWeight()
+	if f.model.IsRegression() {
+		f.target.Append(tv.Value(), weight)
+	}
+	for _, m := range f.members {
+		// Test the member
+		prediction := m.Tree.Predict(inst)
+		m.Stats.Record(inst, prediction)
+		err := f.predictionError(prediction, tv)
+		prediction.Release()
+
+		// Train with a random weight
+		if k := poisson(f.rnd, f.conf.Lambda); k > 0 {
+			winst := helpers.WeightedInstance{Instance: inst, Weight: weight * float64(k)}
+			m.Tree.Train(winst)
+			if m.Background != nil {
+				m.Background.Train(winst)
+			}
+		}
+
+		// Detect warnings and drifts
+		if f.conf.DriftConfidence < 0 {
+			continue
+		}
+		if isIncrease(m.Warning, err) {
+			m.Background = f.newTree()
+			m.Warning = drift.NewADWIN(f.conf.WarningConfidence)
+		}
+		if isIncrease(m.Drift, err) {
+			if m.Tree = m.Background; m.Tree == nil {
+				m.Tree = f.newTree()
+			}
+			m.reset(f)
+		}
+	}
+}
+
+// Predict combines the predictions of all members, weighted by
+// their performance. See Bagging.Predict for details.
+func (f *Forest) Predict(inst core.Instance) core.Prediction {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	isRegression := f.model.IsRegression()
+	predictions := make([]core.Prediction, len(f.members))
+	weights := make([]float64, len(f.members))
+	total := 0.0
+	for i, m := range f.members {
+		predictions[i] = m.Tree.Predict(inst)
+		weights[i] = m.weight()
+		total += weights[i]
+	}
+
+	// Fall back on equal weights if no performance stats are available
+	if total == 0 {
+		weights = nil
+	}
+
+	res := helpers.CombinePredictions(isRegression, predictions, weights)
+	for _, p := range predictions {
+		p.Release()
+	}
+	return res
+}
+
+// DumpTo writes the forest to a writer
+func (f *Forest) DumpTo(w io.Writer) error {
+	enc := msgpack.NewEncoder(w)
+	defer enc.Close()
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return enc.Encode(f)
+}
+
+func (f *Forest) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(f.model, f.members)
+}
+
+func (f *Forest) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&f.model, &f.members)
+}
+
+func (f *Forest) treeConfig() *hoeffding.Config {
+	conf := f.conf.Tree
+	if conf.Subspace == 0 {
+		conf.Subspace = int(math.Sqrt(float64(f.model.NumPredictors()))) + 1
+	}
+	conf.Seed = f.rnd.Int63()
+	return &conf
+}
+
+func (f *Forest) newTree() *hoeffding.Tree {
+	return hoeffding.New(f.model, f.treeConfig())
+}
+
+func (f *Forest) newStats() eval.Evaluator {
+	return eval.NewEvaluator(f.model)
+}
+
+// --------------------------------------------------------------------
+
+type forestMember struct {
+	Tree           *hoeffding.Tree
+	Background     *hoeffding.Tree
+	Warning, Drift *drift.ADWIN
+
+	// Stats track the performance of the member, they are
+	// not persisted
+	Stats eval.Evaluator
+}
+
+// reset resets detectors and stats, drops the background tree
+func (m *forestMember) reset(f *Forest) {
+	m.Background = nil
+	m.Warning = drift.NewADWIN(f.conf.WarningConfidence)
+	m.Drift = drift.NewADWIN(f.conf.DriftConfidence)
+	m.Stats = f.newStats()
+}
+
+// weight returns the voting weight of the member
+func (m *forestMember) weight() float64 {
+	switch e := m.Stats.(type) {
+	case *eval.Classification:
+		return e.Correct()
+	case *eval.Regression:
+		if e.TotalWeight() > 0 {
+			return 1 / math.Max(e.MSE(), 1e-10)
+		}
+	}
+	return 0
+}
+
+func (m *forestMember) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(m.Tree, m.Background, m.Warning, m.Drift)
+}
+
+func (m *forestMember) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&m.Tree, &m.Background, &m.Warning, &m.Drift)
+}
+
+// --------------------------------------------------------------------
+
+// predictionError returns the absolute error of a regression, normalised
+// into [0,1] by the standard deviation of the target as err/(err+stddev),
+// or 0/1 for classifications. ADWIN's bounds assume values in [0,1].
+func (f *Forest) predictionError(prediction core.Prediction, tv core.AttributeValue) float64 {
+	if f.model.IsRegression() {
+		value := prediction.Value()
+		if math.IsNaN(value) {
+			return 0
+		}
+
+		err := math.Abs(tv.Value() - value)
+		if scale := f.target.StdDev(); scale > 0 {
+			return err / (err + scale)
+		} else if err > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	if prediction.Index() == tv.Index() {
+		return 0
+	}
+	return 1
+}
+
+// isIncrease adds an error to a detector and returns true
+// if an increase of the error rate has been detected
+func isIncrease(detector *drift.ADWIN, err float64) bool {
+	before := detector.Estimate()
+	detector.Add(err)
+	return detector.Detected() && detector.Estimate() > before
+}
@@ -0,0 +1,131 @@
+package ensemble
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+
+	"github.com/bsm/reason/classifiers/hoeffding"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/eval"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Forest", func() {
+	model := core.NewModel(
+		&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+		&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+		&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+		&core.Attribute{Name: "z", Kind: core.AttributeKindNumeric},
+		&core.Attribute{Name: "c", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3")},
+	)
+
+	rnd := rand.New(rand.NewSource(1))
+	values := []string{"v1", "v2", "v3"}
+	generate := func(n int, drifted bool) []core.Instance {
+		insts := make([]core.Instance, 0, n)
+		for i := 0; i < n; i++ {
+			x, y, z := rnd.Float64(), rnd.Float64(), rnd.Float64()
+			class := "a"
+			if (x+y > 1) != drifted {
+				class = "b"
+			}
+			insts = append(insts, core.MapInstance{"x": x, "y": y, "z": z, "c": values[rnd.Intn(3)], "class": class})
+		}
+		return insts
+	}
+
+	var subject *Forest
+
+	BeforeEach(func() {
+		subject = NewForest(model, &Config{Seed: 1, Tree: hoeffding.Config{GracePeriod: 50, SplitConfidence: 0.01}})
+	})
+
+	It("should init", func() {
+		Expect(subject.Trees()).To(HaveLen(10))
+		Expect(subject.conf.Lambda).To(Equal(6.0))
+		Expect(subject.treeConfig().Subspace).To(Equal(3))
+	})
+
+	It("should adapt to drifts", func() {
+		for _, inst := range generate(5000, false) {
+			subject.Train(inst)
+		}
+		before := subject.Trees()
+
+		for _, inst := range generate(5000, true) {
+			subject.Train(inst)
+		}
+		replaced := 0
+		for i, tree := range subject.Trees() {
+			if tree != before[i] {
+				replaced++
+			}
+		}
+		Expect(replaced).To(BeNumerically(">", 5))
+
+		stats := eval.NewClassification(model)
+		for _, inst := range generate(1000, true) {
+			stats.Record(inst, subject.Predict(inst))
+		}
+		Expect(stats.Correct()).To(BeNumerically(">", 0.9))
+	})
+
+	It("should predict regressions", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "t", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+		)
+		forest := NewForest(model, &Config{Size: 5, Tree: hoeffding.Config{GracePeriod: 50}})
+		for i := 0; i < 3000; i++ {
+			x, y := rnd.Float64()*10, rnd.Float64()
+			forest.Train(core.MapInstance{"x": x, "y": y, "t": 2*x + rnd.NormFloat64()})
+		}
+
+		prediction := forest.Predict(core.MapInstance{"x": 5.0, "y": 0.5})
+		Expect(prediction).To(HaveLen(1))
+		Expect(prediction.Value()).To(BeNumerically("~", 10, 1.5))
+		Expect(prediction.Top().Variance).To(BeNumerically(">", 0))
+	})
+
+	It("should not reset members on stationary regressions", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "t", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+		)
+		// heavy-tailed errors on a large scale
+		rnd := rand.New(rand.NewSource(1))
+		forest := NewForest(model, &Config{Seed: 1, Tree: hoeffding.Config{GracePeriod: 50}})
+		before := forest.Trees()
+		for i := 0; i < 10000; i++ {
+			x, y := rnd.Float64()*10, rnd.Float64()
+			forest.Train(core.MapInstance{"x": x, "y": y, "t": 1e3 * math.Exp(x/3+rnd.NormFloat64())})
+		}
+
+		for i, tree := range forest.Trees() {
+			Expect(tree).To(BeIdenticalTo(before[i]))
+		}
+	})
+
+	It("should dump/load", func() {
+		train := generate(2000, false)
+		for _, inst := range train {
+			subject.Train(inst)
+		}
+
+		buf := new(bytes.Buffer)
+		Expect(subject.DumpTo(buf)).To(Succeed())
+
+		loaded, err := LoadForest(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Trees()).To(HaveLen(10))
+		Expect(loaded.Model()).To(Equal(model))
+		for _, inst := range train[:100] {
+			Expect(loaded.Predict(inst).Index()).To(Equal(subject.Predict(inst).Index()))
+		}
+	})
+
+})
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/dataset"
 	"github.com/bsm/reason/testdata"
 )
 
@@ -72,6 +73,70 @@ func BenchmarkTree_Train_rpb(b *testing.B) {
 	})
 }
 
+func BenchmarkTree_Predict_cpb(b *testing.B) {
+	benchmarkC(b, benchmarkPredict, func(t *Tree, s []core.Instance) {
+		max := len(s)
+		cnt := int64(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(atomic.AddInt64(&cnt, 1))
+				p := t.Predict(s[i%max])
+				p.Release()
+			}
+		})
+	})
+}
+
+func BenchmarkTree_Predict_rpb(b *testing.B) {
+	benchmarkR(b, benchmarkPredict, func(t *Tree, s []core.Instance) {
+		max := len(s)
+		cnt := int64(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(atomic.AddInt64(&cnt, 1))
+				p := t.Predict(s[i%max])
+				p.Release()
+			}
+		})
+	})
+}
+
+func BenchmarkTree_TrainPredict_cpb(b *testing.B) {
+	benchmarkC(b, benchmarkPredict, func(t *Tree, s []core.Instance) {
+		max := len(s)
+		cnt := int64(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(atomic.AddInt64(&cnt, 1))
+				if i%2 == 0 {
+					t.Train(s[i%max])
+				} else {
+					p := t.Predict(s[i%max])
+					p.Release()
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkTree_TrainPredict_rpb(b *testing.B) {
+	benchmarkR(b, benchmarkPredict, func(t *Tree, s []core.Instance) {
+		max := len(s)
+		cnt := int64(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(atomic.AddInt64(&cnt, 1))
+				if i%2 == 0 {
+					t.Train(s[i%max])
+				} else {
+					p := t.Predict(s[i%max])
+					p.Release()
+				}
+			}
+		})
+	})
+}
+
 func benchmarkC(b *testing.B, bm benchmarkFunc, fn func(*Tree, []core.Instance)) {
 	model := testdata.BigClassificationModel()
 	stream, err := testdata.Open("../../testdata/bigcls.csv", model)
@@ -92,9 +157,9 @@ func benchmarkR(b *testing.B, bm benchmarkFunc, fn func(*Tree, []core.Instance))
 	bm(b, model, stream, fn)
 }
 
-type benchmarkFunc func(*testing.B, *core.Model, *testdata.BigDataStream, func(*Tree, []core.Instance))
+type benchmarkFunc func(*testing.B, *core.Model, *dataset.CSVReader, func(*Tree, []core.Instance))
 
-func benchmarkTrain(b *testing.B, model *core.Model, stream *testdata.BigDataStream, fn func(*Tree, []core.Instance)) {
+func benchmarkTrain(b *testing.B, model *core.Model, stream *dataset.CSVReader, fn func(*Tree, []core.Instance)) {
 	sample, err := stream.ReadN(1000)
 	if err != nil {
 		b.Fatal(err)
@@ -105,7 +170,7 @@ func benchmarkTrain(b *testing.B, model *core.Model, stream *testdata.BigDataStr
 	fn(tree, sample)
 }
 
-func benchmarkPredict(b *testing.B, model *core.Model, stream *testdata.BigDataStream, fn func(*Tree, []core.Instance)) {
+func benchmarkPredict(b *testing.B, model *core.Model, stream *dataset.CSVReader, fn func(*Tree, []core.Instance)) {
 	sample, err := stream.ReadN(50000)
 	if err != nil {
 		b.Fatal(err)
@@ -1,6 +1,93 @@
 package hoeffding
 
-import "github.com/bsm/reason/classifiers"
+import (
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/classifiers/internal/helpers"
+)
+
+// LeafPrediction determines how leaves predict target values
+type LeafPrediction uint8
+
+const (
+	// LeafPredictionMajorityClass predicts the majority class
+	// (or the mean target value for regressions).
+	LeafPredictionMajorityClass LeafPrediction = iota
+	// LeafPredictionNaiveBayes applies Naive Bayes to the leaf's observers.
+	// Classifications only.
+	LeafPredictionNaiveBayes
+	// LeafPredictionNaiveBayesAdaptive tracks the accuracy of majority class
+	// and Naive Bayes predictions at each leaf and uses the more accurate one.
+	// Classifications only.
+	LeafPredictionNaiveBayesAdaptive
+	// LeafPredictionPerceptron uses a linear model, trained online from the
+	// numeric predictors of the instances reaching each leaf.
+	// Regressions only.
+	LeafPredictionPerceptron
+	// LeafPredictionPerceptronAdaptive tracks the accuracy of the mean target
+	// value and the perceptron at each leaf and uses the more accurate one.
+	// Regressions only.
+	LeafPredictionPerceptronAdaptive
+)
+
+func (p LeafPrediction) isRegression() bool {
+	return p == LeafPredictionPerceptron || p == LeafPredictionPerceptronAdaptive
+}
+
+// NumericObserver determines how leaves observe numeric predictors
+type NumericObserver uint8
+
+const (
+	// NumericObserverGaussian approximates the distribution of numeric
+	// predictors and evaluates NumBins equal-width split points between
+	// the observed minimum and maximum.
+	NumericObserverGaussian NumericObserver = iota
+	// NumericObserverEBST stores all distinct values of numeric predictors
+	// in an exhaustive binary search tree and evaluates each value as a
+	// split point. Highest split quality, but memory grows with the number
+	// of distinct values.
+	NumericObserverEBST
+	// NumericObserverSketch maintains a streaming histogram with at most
+	// NumBins bins and evaluates the boundaries between bins as split
+	// points. Memory is bounded, bins adapt to the distribution of values.
+	NumericObserverSketch
+)
+
+// NominalSplit determines how nominal predictors are split
+type NominalSplit uint8
+
+const (
+	// NominalSplitMultiway creates one branch per nominal value.
+	NominalSplitMultiway NominalSplit = iota
+	// NominalSplitBinary creates two branches, one for the single
+	// best value and one for the rest. Suitable for predictors with
+	// many distinct values.
+	NominalSplitBinary
+	// NominalSplitSubset creates two branches, one for the best subset
+	// of values and one for the rest. For classifications, subsets are
+	// only considered if the target is binary, otherwise single values
+	// are separated as with NominalSplitBinary.
+	NominalSplitSubset
+)
+
+// MissingStrategy determines how instances are routed through split nodes
+// if the value of the split predictor is missing
+type MissingStrategy uint8
+
+const (
+	// MissingStrategyStop stops instances at the split node. Such instances
+	// are not learned and are predicted by the split node's distribution.
+	MissingStrategyStop MissingStrategy = iota
+	// MissingStrategyMajority routes instances to the most-populated branch.
+	MissingStrategyMajority
+	// MissingStrategyFractional routes instances to all branches, weighted by
+	// the relative weight of each branch, for training and prediction.
+	// Predictions combine the distributions of all branches accordingly.
+	// See "C4.5: Programs for Machine Learning" by J. Ross Quinlan (1993).
+	MissingStrategyFractional
+	// MissingStrategyBranch routes instances to a dedicated branch, which
+	// learns from instances with missing values only.
+	MissingStrategyBranch
+)
 
 // Config configures behaviour
 type Config struct {
@@ -35,6 +122,79 @@ type Config struct {
 	// Default: 0.05
 	TieThreshold float64
 
+	// The observer used to monitor numeric predictors
+	// Default: NumericObserverGaussian
+	NumericObserver NumericObserver
+
+	// The number of split points evaluated by gaussian observers or the
+	// maximum number of bins maintained by sketch observers
+	// Default: 10
+	NumBins int
+
+	// The split type used for nominal predictors
+	// Default: NominalSplitMultiway
+	NominalSplit NominalSplit
+
+	// The maximum number of intervals numeric predictors may be split
+	// into. Values >2 enable k-interval splits with cut points chosen by
+	// the numeric observer. Their merit is normalised by log2(k) to
+	// compete fairly with binary splits.
+	// Default: 2 (binary)
+	NumIntervals int
+
+	// The strategy used to route instances through split nodes
+	// if the value of the split predictor is missing
+	// Default: MissingStrategyStop
+	MissingStrategy MissingStrategy
+
+	// The maximum number of surrogate splits each split node maintains.
+	// Split nodes track which conditions on other predictors best mimic
+	// their own split condition and route instances by the best applicable
+	// surrogate if the value of the split predictor is missing, before
+	// falling back on MissingStrategy. Trees with surrogates always lock
+	// the entire tree during training.
+	// To disable, set to 0.
+	// Default: 0 (disabled)
+	NumSurrogates int
+
+	// The number of randomly selected predictors each leaf observes and
+	// considers when evaluating splits, as used by random forests.
+	// To consider all predictors, set to 0.
+	// Default: 0 (all)
+	Subspace int
+
+	// The seed of the random number generator used to select predictors
+	// Default: 0
+	Seed int64
+
+	// The prediction strategy used by leaves
+	// Default: LeafPredictionMajorityClass
+	LeafPrediction LeafPrediction
+
+	// The learning rate of perceptron leaves
+	// Default: 0.02
+	LearningRate float64
+
+	// The number of training instances a split node should observe
+	// between re-evaluation attempts. When enabled, the tree follows the
+	// Extremely Fast Decision Tree (EFDT) strategy: split nodes maintain
+	// their observers and replace their split condition once another
+	// predictor outperforms it by the Hoeffding bound. Leaves are split as
+	// soon as the best split outperforms not splitting at all.
+	// To disable, set to 0.
+	// Default: 0 (disabled)
+	ReEvalPeriod int
+
+	// Enables Hoeffding Adaptive Tree (HAT) mode. Split nodes monitor
+	// the error rate of their subtrees using ADWIN and start growing
+	// alternate subtrees once an increase is detected. Alternates replace
+	// the original subtrees when they become significantly more accurate.
+	// Regression trees follow the FIMT-DD strategy instead, detecting
+	// increases of the normalised absolute error with the Page-Hinkley
+	// test and comparing faded squared errors of alternates.
+	// Default: false
+	Adaptive bool
+
 	// By enabling this option, tracing notification events will be
 	// emitted via the Traces channel after each training cycle. This
 	// is for debug purposes only. When enabled, you must consume
@@ -56,10 +216,52 @@ func (c *Config) norm(isRegression bool) {
 	if c.SplitConfidence <= 0 {
 		c.SplitConfidence = 1e-7
 	}
+	if c.Subspace < 0 {
+		c.Subspace = 0
+	}
+	if c.NumSurrogates < 0 {
+		c.NumSurrogates = 0
+	}
+	if c.ReEvalPeriod < 0 {
+		c.ReEvalPeriod = 0
+	}
 	if c.TieThreshold <= 0 {
 		c.TieThreshold = 0.05
 	}
 	if c.SplitCriterion == nil {
 		c.SplitCriterion = classifiers.DefaultSplitCriterion(isRegression)
 	}
+	if c.NumBins <= 0 {
+		c.NumBins = 10
+	}
+	if c.NumIntervals < 2 {
+		c.NumIntervals = 2
+	}
+	if c.LearningRate <= 0 {
+		c.LearningRate = 0.02
+	}
+	if isRegression != c.LeafPrediction.isRegression() {
+		c.LeafPrediction = LeafPredictionMajorityClass
+	}
+}
+
+func (c *Config) observerConfig() *helpers.ObserverConfig {
+	conf := &helpers.ObserverConfig{NumBins: c.NumBins, NumIntervals: c.NumIntervals}
+	switch c.NumericObserver {
+	case NumericObserverEBST:
+		conf.Numeric = helpers.NumericObserverEBST
+	case NumericObserverSketch:
+		conf.Numeric = helpers.NumericObserverSketch
+	default:
+		conf.Numeric = helpers.NumericObserverGaussian
+	}
+	switch c.NominalSplit {
+	case NominalSplitBinary:
+		conf.Nominal = helpers.NominalSplitBinary
+	case NominalSplitSubset:
+		conf.Nominal = helpers.NominalSplitSubset
+	default:
+		conf.Nominal = helpers.NominalSplitMultiway
+	}
+	return conf
 }
@@ -0,0 +1,33 @@
+package hoeffding
+
+import (
+	"github.com/bsm/reason/classifiers/internal/helpers"
+	"github.com/bsm/reason/core"
+)
+
+// combinePredictions combines the predictions of multiple branches,
+// weighted by fractions. For classifications, the normalised class
+// distributions are combined and scaled by the total weight of all
+// branches. For regressions, the result is the mixture of the predicted
+// values.
+func combinePredictions(isRegression bool, predictions []core.Prediction, fractions []float64) core.Prediction {
+	res := helpers.CombinePredictions(isRegression, predictions, fractions)
+
+	total, sum := 0.0, 0.0
+	for i, p := range predictions {
+		if fractions[i] > 0 {
+			for _, pv := range p {
+				total += pv.Votes
+			}
+		}
+	}
+	for _, pv := range res {
+		sum += pv.Votes
+	}
+	if sum > 0 {
+		for i := range res {
+			res[i].Votes *= total / sum
+		}
+	}
+	return res
+}
@@ -4,9 +4,12 @@ import (
 	"bufio"
 	"fmt"
 	"math"
+	"sort"
+	"sync"
 
 	"github.com/bsm/reason/classifiers/internal/helpers"
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
 	"github.com/bsm/reason/internal/msgpack"
 )
 
@@ -15,13 +18,31 @@ func init() {
 	msgpack.Register(7749, (*splitNode)(nil))
 }
 
+// Encoding format versions of nodes, unversioned dumps
+// were created before nodes stored any adaptive state
+const (
+	leafNodeVersion  = 1
+	splitNodeVersion = 1
+)
+
+// missingBranch is the index of the dedicated branch for
+// instances with missing values (MissingStrategyBranch only)
+const missingBranch = -2
+
+// Settings for the evaluation of alternate subtrees in adaptive regression trees
+const (
+	alternateFadingFactor = 0.995
+	alternateEvalPeriod   = 150
+	alternateMaxAge       = 1500
+)
+
 var (
 	_ treeNode = (*leafNode)(nil)
 	_ treeNode = (*splitNode)(nil)
 )
 
 type treeNode interface {
-	Filter(inst core.Instance, parent *splitNode, parentIndex int) (treeNode, *splitNode, int)
+	Filter(inst core.Instance, missing MissingStrategy, parent *splitNode, parentIndex int) (treeNode, *splitNode, int)
 	Prune(isObsolete PruneEval, parent *splitNode)
 	WriteGraph(*bufio.Writer, string) error
 	WriteText(*bufio.Writer, string) error
@@ -62,6 +83,16 @@ type leafNode struct {
 
 	IsInactive       bool
 	WeightOnLastEval float64
+
+	// MCCorrect and NBCorrect track the weight of instances correctly
+	// predicted by majority class and by Naive Bayes respectively
+	MCCorrect, NBCorrect float64
+
+	// Perceptron is a linear model (perceptron leaves only)
+	Perceptron *perceptron
+
+	// mu protects the leaf's stats when training concurrently
+	mu sync.RWMutex
 }
 
 func newLeafNode(stats helpers.ObservationStats) *leafNode {
@@ -71,8 +102,19 @@ func newLeafNode(stats helpers.ObservationStats) *leafNode {
 	}
 }
 
-func (n *leafNode) TotalWeight() float64     { return n.Stats.TotalWeight() }
-func (n *leafNode) Predict() core.Prediction { return n.Stats.State() }
+func (n *leafNode) TotalWeight() float64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.Stats.TotalWeight()
+}
+
+func (n *leafNode) Predict() core.Prediction {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.Stats.State()
+}
 
 func (n *leafNode) ReadInfo(depth int, info *TreeInfo) {
 	info.NumNodes++
@@ -87,7 +129,7 @@ func (n *leafNode) ReadInfo(depth int, info *TreeInfo) {
 	}
 }
 
-func (n *leafNode) Filter(_ core.Instance, parent *splitNode, parentIndex int) (treeNode, *splitNode, int) {
+func (n *leafNode) Filter(_ core.Instance, _ MissingStrategy, parent *splitNode, parentIndex int) (treeNode, *splitNode, int) {
 	return n, parent, parentIndex
 }
 
@@ -116,7 +158,12 @@ func (n *leafNode) ByteSize() int {
 		size += 24
 	}
 	for _, obs := range n.Observers {
-		size += obs.ByteSize()
+		if obs != nil {
+			size += obs.ByteSize()
+		}
+	}
+	if n.Perceptron != nil {
+		size += n.Perceptron.ByteSize()
 	}
 	return size
 }
@@ -132,6 +179,8 @@ func (n *leafNode) Activate() {
 	}
 }
 
+// Learn updates the leaf with an instance. The caller must either hold
+// an exclusive lock on the tree or on the leaf.
 func (n *leafNode) Learn(inst core.Instance, tree *Tree) {
 	// Get the target value, skip this instance if missing
 	tv := tree.model.Target().Value(inst)
@@ -139,8 +188,31 @@ func (n *leafNode) Learn(inst core.Instance, tree *Tree) {
 		return
 	}
 
-	// Get instance weight and update pre-split distribution stats
+	// Get instance weight, track prediction accuracy if required
 	weight := inst.GetInstanceWeight()
+	if tree.conf.LeafPrediction == LeafPredictionNaiveBayesAdaptive && !n.IsInactive {
+		mc := n.Stats.State()
+		if mc.Index() == tv.Index() {
+			n.MCCorrect += weight
+		}
+		mc.Release()
+
+		nb := n.naiveBayes(inst, tree)
+		if nb.Index() == tv.Index() {
+			n.NBCorrect += weight
+		}
+		nb.Release()
+	}
+
+	// Train perceptron if required
+	if tree.conf.LeafPrediction.isRegression() {
+		if n.Perceptron == nil {
+			n.Perceptron = newPerceptron(tree.model.NumPredictors())
+		}
+		n.Perceptron.Learn(inst, tree.model.Predictors(), tv, weight, tree.conf.LearningRate)
+	}
+
+	// Update pre-split distribution stats
 	n.Stats.UpdatePreSplit(tv, weight)
 
 	// Skip the remaining steps if this node is inactive
@@ -148,51 +220,135 @@ func (n *leafNode) Learn(inst core.Instance, tree *Tree) {
 		return
 	}
 
-	// Update each predictor's observer with a target-value, predictor-value
-	// and weight tuple
-	predictors := tree.model.Predictors()
+	// Update each predictor's observer
 	if n.Observers == nil {
-		n.Observers = make([]helpers.Observer, len(predictors))
-		for i, predictor := range predictors {
-			n.Observers[i] = n.Stats.NewObserver(predictor.IsNominal())
-		}
+		n.Observers = tree.newObservers(n.Stats)
 	}
-	for i, predictor := range predictors {
-		pv := predictor.Value(inst)
-		if pv.IsMissing() {
-			continue
+	observe(n.Observers, inst, tv, weight, tree.model.Predictors())
+}
+
+// PredictInstance returns the prediction for an instance using the
+// configured leaf prediction strategy
+func (n *leafNode) PredictInstance(inst core.Instance, tree *Tree) core.Prediction {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.predict(inst, tree)
+}
+
+func (n *leafNode) predict(inst core.Instance, tree *Tree) core.Prediction {
+	switch tree.conf.LeafPrediction {
+	case LeafPredictionNaiveBayes:
+		return n.naiveBayes(inst, tree)
+	case LeafPredictionNaiveBayesAdaptive:
+		if n.NBCorrect >= n.MCCorrect {
+			return n.naiveBayes(inst, tree)
+		}
+	case LeafPredictionPerceptron:
+		return n.predictPerceptron(inst, tree)
+	case LeafPredictionPerceptronAdaptive:
+		if n.Perceptron != nil && n.Perceptron.IsMoreAccurate() {
+			return n.predictPerceptron(inst, tree)
 		}
-		n.Observers[i].Observe(tv, pv, weight)
 	}
+	return n.Stats.State()
 }
 
 func (n *leafNode) BestSplits(tree *Tree) helpers.SplitSuggestions {
 	if n.IsInactive {
 		return nil
 	}
+	return bestSplits(n.Stats, n.Observers, tree)
+}
 
-	// Init split-suggestions, including a null suggestion
-	suggestions := make(helpers.SplitSuggestions, 1, len(n.Observers)+1)
+func (n *leafNode) FindLeaves(acc leafNodeSlice) leafNodeSlice { return append(acc, n) }
 
-	// Calculate a split suggestion for each of the observed predictors
-	predictors := tree.model.Predictors()
-	for i, obs := range n.Observers {
-		split := n.Stats.BestSplit(tree.conf.SplitCriterion, obs, predictors[i])
-		suggestions = append(suggestions, split)
+func (n *leafNode) EncodeTo(enc *msgpack.Encoder) error {
+	if err := enc.EncodeVersion(leafNodeVersion); err != nil {
+		return err
 	}
+	return enc.Encode(n.Stats, n.Observers, n.WeightOnLastEval, n.IsInactive, n.MCCorrect, n.NBCorrect, n.Perceptron)
+}
 
-	// Rank the suggestions by merit
-	return suggestions.Rank()
+func (n *leafNode) DecodeFrom(dec *msgpack.Decoder) error {
+	version, err := dec.DecodeVersion()
+	if err != nil {
+		return err
+	}
+
+	// unversioned nodes do not track leaf prediction accuracies
+	if err := dec.Decode(&n.Stats, &n.Observers, &n.WeightOnLastEval, &n.IsInactive); err != nil || version == 0 {
+		return err
+	}
+	return dec.Decode(&n.MCCorrect, &n.NBCorrect, &n.Perceptron)
 }
 
-func (n *leafNode) FindLeaves(acc leafNodeSlice) leafNodeSlice { return append(acc, n) }
+// naiveBayes calculates class votes by applying Naive Bayes to the
+// observed class distribution and the predictor observers. It falls
+// back on the majority class votes if observers are unavailable.
+func (n *leafNode) naiveBayes(inst core.Instance, tree *Tree) core.Prediction {
+	prediction := n.Stats.State()
+	total := n.Stats.TotalWeight()
+	if len(n.Observers) == 0 || total <= 0 {
+		return prediction
+	}
 
-func (n *leafNode) EncodeTo(enc *msgpack.Encoder) error {
-	return enc.Encode(n.Stats, n.Observers, n.WeightOnLastEval, n.IsInactive)
+	// Calculate log-likelihoods to avoid underflows
+	logs := make([]float64, len(prediction))
+	max := math.Inf(-1)
+	for i, pv := range prediction {
+		logs[i] = math.Log(pv.Votes / total)
+		for j, predictor := range tree.model.Predictors() {
+			obs, ok := n.Observers[j].(helpers.CObserver)
+			if !ok {
+				continue
+			}
+
+			val := predictor.Value(inst)
+			if val.IsMissing() {
+				continue
+			}
+
+			prob := obs.Probability(pv.AttributeValue, val)
+			if math.IsNaN(prob) || prob <= 0 {
+				logs[i] = math.Inf(-1)
+				break
+			}
+			logs[i] += math.Log(prob)
+		}
+		if logs[i] > max {
+			max = logs[i]
+		}
+	}
+	if math.IsInf(max, -1) {
+		return prediction
+	}
+
+	// Normalise to posterior probabilities and scale by total weight
+	sum := 0.0
+	for i := range logs {
+		logs[i] = math.Exp(logs[i] - max)
+		sum += logs[i]
+	}
+	for i := range prediction {
+		prediction[i].Votes = logs[i] / sum * total
+	}
+	return prediction
 }
 
-func (n *leafNode) DecodeFrom(dec *msgpack.Decoder) error {
-	return dec.Decode(&n.Stats, &n.Observers, &n.WeightOnLastEval, &n.IsInactive)
+// predictPerceptron predicts the target value using the perceptron. It
+// falls back on the mean target value if the perceptron is unavailable.
+func (n *leafNode) predictPerceptron(inst core.Instance, tree *Tree) core.Prediction {
+	prediction := n.Stats.State()
+	if n.Perceptron == nil || n.Perceptron.Target.IsZero() {
+		return prediction
+	}
+
+	prediction[0].AttributeValue = core.AttributeValue(n.Perceptron.Predict(inst, tree.model.Predictors()))
+	if variance := n.Perceptron.Variance(); !math.IsNaN(variance) {
+		prediction[0].Variance = variance
+	}
+	return prediction
 }
 
 // --------------------------------------------------------------------
@@ -201,6 +357,27 @@ type splitNode struct {
 	Stats     helpers.ObservationStats
 	Condition helpers.SplitCondition
 	Children  map[int]treeNode
+
+	// Observers and WeightOnLastEval are used to
+	// re-evaluate the split condition (EFDT only)
+	Observers        []helpers.Observer
+	WeightOnLastEval float64
+
+	// Errors and AltErrors monitor the error rates of this node's
+	// subtree and its alternate (adaptive classification trees only)
+	Errors, AltErrors *drift.ADWIN
+	Alternate         treeNode
+
+	// Drift monitors the normalised absolute error of this node's
+	// subtree, Loss and AltLoss track the faded squared errors of
+	// the subtree and its alternate (adaptive regression trees only)
+	Drift         *drift.PageHinkley
+	Loss, AltLoss float64
+	AltWeight     float64
+
+	// Surrogates track conditions on other predictors which
+	// mimic the split condition (surrogate trees only)
+	Surrogates *surrogates
 }
 
 func newSplitNode(condition helpers.SplitCondition, preSplit helpers.ObservationStats, postSplit map[int]helpers.ObservationStats) *splitNode {
@@ -222,22 +399,97 @@ func (n *splitNode) ByteSize() int {
 		size += 8
 		size += c.ByteSize()
 	}
+	if n.Observers != nil {
+		size += 24
+	}
+	for _, obs := range n.Observers {
+		if obs != nil {
+			size += obs.ByteSize()
+		}
+	}
+	if n.Alternate != nil {
+		size += n.Alternate.ByteSize()
+	}
+	size += n.Surrogates.ByteSize()
 	return size
 }
 
 func (n *splitNode) TotalWeight() float64     { return n.Stats.TotalWeight() }
 func (n *splitNode) Predict() core.Prediction { return n.Stats.State() }
 
-func (n *splitNode) Filter(inst core.Instance, parent *splitNode, parentIndex int) (treeNode, *splitNode, int) {
-	if branch := n.Condition.Branch(inst); branch > -1 {
+func (n *splitNode) Filter(inst core.Instance, missing MissingStrategy, parent *splitNode, parentIndex int) (treeNode, *splitNode, int) {
+	if branch := n.branch(inst, missing); branch != -1 {
 		if child, ok := n.Children[branch]; ok {
-			return child.Filter(inst, n, branch)
+			return child.Filter(inst, missing, n, branch)
 		}
 		return nil, n, branch
 	}
 	return n, parent, parentIndex
 }
 
+// branch returns the index of the branch an instance follows
+// or -1 if the instance stops at this node
+func (n *splitNode) branch(inst core.Instance, missing MissingStrategy) int {
+	if branch := n.Condition.Branch(inst); branch > -1 {
+		return branch
+	}
+	if branch := n.Surrogates.Branch(inst); branch > -1 {
+		return branch
+	}
+
+	switch missing {
+	case MissingStrategyMajority:
+		branches, fractions := n.fractions()
+		best := -1
+		for i := range branches {
+			if best < 0 || fractions[i] > fractions[best] {
+				best = i
+			}
+		}
+		if best > -1 {
+			return branches[best]
+		}
+	case MissingStrategyBranch:
+		return missingBranch
+	}
+	return -1
+}
+
+// fractions returns the sorted indices of all regular branches
+// and their fractions of the total weight
+func (n *splitNode) fractions() ([]int, []float64) {
+	branches := make([]int, 0, len(n.Children))
+	for i := range n.Children {
+		if i != missingBranch {
+			branches = append(branches, i)
+		}
+	}
+	sort.Ints(branches)
+
+	sum := 0.0
+	fractions := make([]float64, len(branches))
+	for i, branch := range branches {
+		fractions[i] = n.Children[branch].TotalWeight()
+		sum += fractions[i]
+	}
+	for i := range fractions {
+		if sum > 0 {
+			fractions[i] /= sum
+		} else {
+			fractions[i] = 1 / float64(len(fractions))
+		}
+	}
+	return branches, fractions
+}
+
+// describe describes a branch
+func (n *splitNode) describe(branch int) string {
+	if branch == missingBranch {
+		return "is missing"
+	}
+	return n.Condition.Describe(branch)
+}
+
 func (n *splitNode) Prune(isObsolete PruneEval, parent *splitNode) {
 	for _, child := range n.Children {
 		child.Prune(isObsolete, n)
@@ -258,8 +510,11 @@ func (n *splitNode) WriteGraph(w *bufio.Writer, nodeName string) error {
 
 	for i, child := range n.Children {
 		subName := fmt.Sprintf("%s_%d", nodeName, i)
+		if i == missingBranch {
+			subName = nodeName + "_m"
+		}
 
-		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%q];\n", nodeName, subName, n.Condition.Describe(i)); err != nil {
+		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%q];\n", nodeName, subName, n.describe(i)); err != nil {
 			return err
 		}
 		if err := child.WriteGraph(w, subName); err != nil {
@@ -273,11 +528,14 @@ func (n *splitNode) WriteText(w *bufio.Writer, indent string) error {
 	if _, err := fmt.Fprintf(w, " -> %.2f (%.0f)\n", n.Predict().Value(), n.TotalWeight()); err != nil {
 		return err
 	}
+	if err := n.Surrogates.WriteText(w, indent); err != nil {
+		return err
+	}
 
 	name := n.Condition.Predictor()
 	sind := indent + "\t"
 	for i, child := range n.Children {
-		if _, err := fmt.Fprintf(w, "%s%s %q", indent, name, n.Condition.Describe(i)); err != nil {
+		if _, err := fmt.Fprintf(w, "%s%s %q", indent, name, n.describe(i)); err != nil {
 			return err
 		}
 
@@ -294,13 +552,203 @@ func (n *splitNode) FindLeaves(acc leafNodeSlice) leafNodeSlice {
 	for _, c := range n.Children {
 		acc = c.FindLeaves(acc)
 	}
+	if n.Alternate != nil {
+		acc = n.Alternate.FindLeaves(acc)
+	}
 	return acc
 }
 
+// Learn updates the node's stats and observers with an instance
+func (n *splitNode) Learn(inst core.Instance, tree *Tree) {
+	tv := tree.model.Target().Value(inst)
+	if tv.IsMissing() {
+		return
+	}
+
+	weight := inst.GetInstanceWeight()
+	n.Stats.UpdatePreSplit(tv, weight)
+	if n.Observers == nil {
+		n.Observers = tree.newObservers(n.Stats)
+	}
+	observe(n.Observers, inst, tv, weight, tree.model.Predictors())
+}
+
+// LearnSurrogates updates the node's surrogates with an instance
+func (n *splitNode) LearnSurrogates(inst core.Instance, tree *Tree) {
+	branch := n.Condition.Branch(inst)
+	if branch < 0 {
+		return
+	}
+
+	if n.Surrogates == nil {
+		n.Surrogates = newSurrogates(tree, n.Condition.Predictor())
+	}
+	n.Surrogates.Learn(inst, branch, tree)
+}
+
+// Adapt updates the error estimates of the node and manages its alternate
+// subtree. It returns the alternate subtree if it has outperformed the
+// original and should replace this node.
+func (n *splitNode) Adapt(inst core.Instance, tv core.AttributeValue, tree *Tree) treeNode {
+	if tree.model.IsRegression() {
+		return n.adaptRegression(inst, tv, tree)
+	}
+
+	if n.Errors == nil {
+		n.Errors = drift.NewADWIN(0)
+	}
+
+	// Update error estimate, start a new alternate
+	// if the error rate has increased and none exists
+	before := n.Errors.Estimate()
+	n.Errors.Add(predictionError(n, inst, tv, tree))
+	if n.Errors.Detected() && n.Errors.Estimate() > before && n.Alternate == nil {
+		n.Alternate = newLeafNode(helpers.NewObservationStats(tree.model.IsRegression()))
+		n.AltErrors = drift.NewADWIN(0)
+	} else if n.Alternate != nil && n.Errors.Width() > 300 && n.AltErrors.Width() > 300 {
+		rate, altRate := n.Errors.Estimate(), n.AltErrors.Estimate()
+		fn := 1/float64(n.Errors.Width()) + 1/float64(n.AltErrors.Width())
+		bound := math.Sqrt(2 * rate * (1 - rate) * math.Log(2/0.05) * fn)
+
+		if bound < rate-altRate {
+			alt := n.Alternate
+			n.Alternate, n.AltErrors = nil, nil
+			return alt
+		} else if bound < altRate-rate {
+			n.Alternate, n.AltErrors = nil, nil
+		}
+	}
+
+	// Test, then train the alternate
+	if n.Alternate != nil {
+		n.AltErrors.Add(predictionError(n.Alternate, inst, tv, tree))
+		n.Alternate, _ = tree.train(n.Alternate, inst)
+	}
+	return nil
+}
+
+// adaptRegression follows the FIMT-DD strategy, see Adapt.
+func (n *splitNode) adaptRegression(inst core.Instance, tv core.AttributeValue, tree *Tree) treeNode {
+	if n.Drift == nil {
+		n.Drift = drift.NewPageHinkley(0, 0)
+	}
+
+	// Update drift detection, start a new alternate if the
+	// normalised error has increased
+	state := n.Stats.State()
+	scale := 3 * math.Sqrt(state.Top().Variance)
+	state.Release()
+
+	diff := predictionDiff(n, inst, tv, tree)
+	if scale > 0 {
+		n.Drift.Add(math.Abs(diff) / scale)
+	}
+	if n.Drift.Detected() && n.Alternate == nil {
+		n.Alternate = newLeafNode(helpers.NewObservationStats(tree.model.IsRegression()))
+		n.Loss, n.AltLoss, n.AltWeight = 0, 0, 0
+	}
+	if n.Alternate == nil {
+		return nil
+	}
+
+	// Compare faded losses periodically, replace this node if the
+	// alternate is more accurate or discard the alternate if it
+	// failed to catch up in time
+	if altDiff := predictionDiff(n.Alternate, inst, tv, tree); !math.IsNaN(altDiff) {
+		n.Loss = n.Loss*alternateFadingFactor + diff*diff
+		n.AltLoss = n.AltLoss*alternateFadingFactor + altDiff*altDiff
+
+		if n.AltWeight++; math.Mod(n.AltWeight, alternateEvalPeriod) == 0 {
+			if n.AltLoss < n.Loss {
+				alt := n.Alternate
+				n.Alternate = nil
+				return alt
+			} else if n.AltWeight >= alternateMaxAge {
+				n.Alternate = nil
+			}
+		}
+	}
+
+	// Train the alternate
+	if n.Alternate != nil {
+		n.Alternate, _ = tree.train(n.Alternate, inst)
+	}
+	return nil
+}
+
 func (n *splitNode) EncodeTo(enc *msgpack.Encoder) error {
-	return enc.Encode(n.Stats, n.Condition, n.Children)
+	if err := enc.EncodeVersion(splitNodeVersion); err != nil {
+		return err
+	}
+	return enc.Encode(n.Stats, n.Condition, n.Children, n.Observers, n.WeightOnLastEval, n.Errors, n.AltErrors, n.Alternate, n.Drift, n.Loss, n.AltLoss, n.AltWeight, n.Surrogates)
 }
 
 func (n *splitNode) DecodeFrom(dec *msgpack.Decoder) error {
-	return dec.Decode(&n.Stats, &n.Condition, &n.Children)
+	version, err := dec.DecodeVersion()
+	if err != nil {
+		return err
+	}
+
+	// unversioned nodes only consist of stats, condition and children
+	if err := dec.Decode(&n.Stats, &n.Condition, &n.Children); err != nil || version == 0 {
+		return err
+	}
+	return dec.Decode(&n.Observers, &n.WeightOnLastEval, &n.Errors, &n.AltErrors, &n.Alternate, &n.Drift, &n.Loss, &n.AltLoss, &n.AltWeight, &n.Surrogates)
+}
+
+// --------------------------------------------------------------------
+
+// observe updates each predictor's observer with a target-value,
+// predictor-value and weight tuple. Predictors without observers
+// are skipped.
+func observe(observers []helpers.Observer, inst core.Instance, tv core.AttributeValue, weight float64, predictors []*core.Attribute) {
+	for i, predictor := range predictors {
+		if observers[i] == nil {
+			continue
+		}
+
+		pv := predictor.Value(inst)
+		if pv.IsMissing() {
+			continue
+		}
+		observers[i].Observe(tv, pv, weight)
+	}
+}
+
+// bestSplits calculates split suggestions for each of the observed
+// predictors, including a null suggestion, ranked by merit.
+func bestSplits(stats helpers.ObservationStats, observers []helpers.Observer, tree *Tree) helpers.SplitSuggestions {
+	suggestions := make(helpers.SplitSuggestions, 1, len(observers)+1)
+
+	predictors := tree.model.Predictors()
+	for i, obs := range observers {
+		if obs == nil {
+			continue
+		}
+
+		split := stats.BestSplit(tree.conf.SplitCriterion, obs, predictors[i])
+		suggestions = append(suggestions, split)
+	}
+	return suggestions.Rank()
+}
+
+// predictionError returns 1 if the subtree at node mispredicts the
+// target value of the instance, 0 otherwise
+func predictionError(node treeNode, inst core.Instance, tv core.AttributeValue, tree *Tree) float64 {
+	prediction := tree.predict(node, inst)
+	defer prediction.Release()
+
+	if prediction.Index() == tv.Index() {
+		return 0.0
+	}
+	return 1.0
+}
+
+// predictionDiff returns the difference between the target value and
+// the value predicted by the subtree at node
+func predictionDiff(node treeNode, inst core.Instance, tv core.AttributeValue, tree *Tree) float64 {
+	prediction := tree.predict(node, inst)
+	defer prediction.Release()
+
+	return tv.Value() - prediction.Value()
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bsm/reason/classifiers/internal/helpers"
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
 	"github.com/bsm/reason/internal/msgpack"
 	"github.com/bsm/reason/testdata"
 	. "github.com/onsi/ginkgo"
@@ -47,6 +48,47 @@ var _ = Describe("leafNode", func() {
 			Expect(subject.ByteSize()).To(BeNumerically("~", 940, 20))
 		})
 
+		It("should predict using naive bayes", func() {
+			inst := core.MapInstance{"outlook": "sunny", "temp": "cool", "humidity": "high", "windy": "true"}
+
+			mc := subject.PredictInstance(inst, tree)
+			Expect(mc.Index()).To(Equal(0))
+			Expect(mc.Top().Votes).To(Equal(9.0))
+
+			nb := subject.PredictInstance(inst, New(model, &Config{LeafPrediction: LeafPredictionNaiveBayes}))
+			Expect(nb.Index()).To(Equal(1))
+			Expect(nb.Top().Votes).To(BeNumerically("~", 8.28, 0.01))
+			Expect(nb[0].Votes + nb[1].Votes).To(BeNumerically("~", 14.0, 0.001))
+		})
+
+		It("should observe random predictor subsets", func() {
+			random := New(model, &Config{Subspace: 2, Seed: 1})
+			leaf := newLeafNode(helpers.NewObservationStats(model.IsRegression()))
+			for _, inst := range instances {
+				leaf.Learn(inst, random)
+			}
+			Expect(leaf.Observers).To(HaveLen(4))
+
+			observed := 0
+			for _, obs := range leaf.Observers {
+				if obs != nil {
+					observed++
+				}
+			}
+			Expect(observed).To(Equal(2))
+			Expect(leaf.BestSplits(random)).To(HaveLen(3))
+		})
+
+		It("should track accuracy for adaptive naive bayes", func() {
+			adaptive := New(model, &Config{LeafPrediction: LeafPredictionNaiveBayesAdaptive})
+			leaf := newLeafNode(helpers.NewObservationStats(model.IsRegression()))
+			for _, inst := range instances {
+				leaf.Learn(inst, adaptive)
+			}
+			Expect(leaf.MCCorrect).To(Equal(8.0))
+			Expect(leaf.NBCorrect).To(Equal(7.0))
+		})
+
 		It("should calc best split", func() {
 			splits := subject.BestSplits(tree)
 			Expect(splits).To(HaveLen(5))
@@ -69,6 +111,27 @@ var _ = Describe("leafNode", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(out).To(Equal(subject))
 		})
+
+		It("should decode unversioned nodes", func() {
+			buf := bytes.NewBuffer([]byte{0xd5, 8, 0x1e, 0x44}) // type code 7748
+			enc := msgpack.NewEncoder(buf)
+			err := enc.Encode(subject.Stats, subject.Observers, subject.WeightOnLastEval, subject.IsInactive, "next")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enc.Close()).NotTo(HaveOccurred())
+
+			var out *leafNode
+			var next string
+			err = msgpack.NewDecoder(buf).
+				WithContext(func(ctx context.Context) context.Context {
+					return context.WithValue(ctx, core.ModelContextKey, model)
+				}).Decode(&out, &next)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Stats).To(Equal(subject.Stats))
+			Expect(out.Observers).To(Equal(subject.Observers))
+			Expect(out.MCCorrect).To(Equal(0.0))
+			Expect(out.Perceptron).To(BeNil())
+			Expect(next).To(Equal("next"))
+		})
 	})
 
 	Describe("regression", func() {
@@ -150,4 +213,50 @@ var _ = Describe("splitNode", func() {
 		Expect(out).To(Equal(subject))
 	})
 
+	It("should decode unversioned nodes", func() {
+		buf := bytes.NewBuffer([]byte{0xd5, 8, 0x1e, 0x45}) // type code 7749
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(subject.Stats, subject.Condition, subject.Children, "next")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out *splitNode
+		var next string
+		err = msgpack.NewDecoder(buf).
+			WithContext(func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, core.ModelContextKey, model)
+			}).Decode(&out, &next)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+		Expect(next).To(Equal("next"))
+	})
+
+	It("should not reset existing alternates", func() {
+		tree := New(model, &Config{Adaptive: true})
+		inst := testdata.ClassificationData()[0]
+		tv := model.Target().Value(inst)
+
+		// an empty subtree mispredicts every instance
+		subject.Errors = drift.NewADWIN(0)
+		for i := 0; i < 1000; i++ {
+			subject.Errors.Add(0)
+		}
+
+		var detections int
+		for i := 0; i < 200; i++ {
+			alt, altErrors := subject.Alternate, subject.AltErrors
+			Expect(subject.Adapt(inst, tv, tree)).To(BeNil())
+			if !subject.Errors.Detected() {
+				continue
+			}
+
+			Expect(subject.Alternate).NotTo(BeNil())
+			if detections++; detections > 1 {
+				Expect(subject.Alternate).To(BeIdenticalTo(alt))
+				Expect(subject.AltErrors).To(BeIdenticalTo(altErrors))
+			}
+		}
+		Expect(detections).To(BeNumerically(">", 1))
+	})
+
 })
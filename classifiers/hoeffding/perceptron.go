@@ -0,0 +1,157 @@
+package hoeffding
+
+import (
+	"math"
+
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+)
+
+func init() {
+	msgpack.Register(7745, (*perceptron)(nil))
+}
+
+const perceptronFadingFactor = 0.99
+
+// perceptron is a linear model which is trained online from the numeric
+// predictors, using the delta rule. Inputs and target are normalised by
+// their observed mean and standard deviation. See "Learning model trees
+// from evolving data streams" by Ikonomovska et al. (2011).
+type perceptron struct {
+	Weights []float64 // one per predictor, plus bias
+	Inputs  []*util.NumSeries
+	Target  *util.NumSeries
+
+	// Faded absolute errors of the perceptron and of the
+	// target mean, faded squared error and faded weight
+	Error, MeanError, SquaredError, ErrorWeight float64
+}
+
+func newPerceptron(numPredictors int) *perceptron {
+	inputs := make([]*util.NumSeries, numPredictors)
+	for i := range inputs {
+		inputs[i] = new(util.NumSeries)
+	}
+	return &perceptron{
+		Weights: make([]float64, numPredictors+1),
+		Inputs:  inputs,
+		Target:  new(util.NumSeries),
+	}
+}
+
+// Clone creates a copy of the perceptron with reset error stats
+func (p *perceptron) Clone() *perceptron {
+	inputs := make([]*util.NumSeries, len(p.Inputs))
+	for i, s := range p.Inputs {
+		series := *s
+		inputs[i] = &series
+	}
+	target := *p.Target
+
+	return &perceptron{
+		Weights: append([]float64(nil), p.Weights...),
+		Inputs:  inputs,
+		Target:  &target,
+	}
+}
+
+// ByteSize estimates the required heap-size
+func (p *perceptron) ByteSize() int {
+	return 112 + 8*len(p.Weights) + 32*len(p.Inputs)
+}
+
+// IsMoreAccurate returns true if the perceptron has been
+// more accurate than the target mean
+func (p *perceptron) IsMoreAccurate() bool {
+	return p.ErrorWeight > 0 && p.Error <= p.MeanError
+}
+
+// Variance returns the faded mean squared error of the perceptron
+func (p *perceptron) Variance() float64 {
+	if p.ErrorWeight > 0 {
+		return p.SquaredError / p.ErrorWeight
+	}
+	return math.NaN()
+}
+
+// Predict predicts the target value of an instance
+func (p *perceptron) Predict(inst core.Instance, predictors []*core.Attribute) float64 {
+	mean, sd := p.Target.Mean(), p.Target.StdDev()
+	if !(sd > 0) {
+		return mean
+	}
+	return p.predictNormal(inst, predictors)*3*sd + mean
+}
+
+// Learn updates the error stats, normalisation stats and weights
+// with a target value and weight
+func (p *perceptron) Learn(inst core.Instance, predictors []*core.Attribute, tv core.AttributeValue, weight, rate float64) {
+	target := tv.Value()
+
+	// Test, then train
+	if !p.Target.IsZero() {
+		diff := target - p.Predict(inst, predictors)
+		p.Error = p.Error*perceptronFadingFactor + math.Abs(diff)*weight
+		p.MeanError = p.MeanError*perceptronFadingFactor + math.Abs(target-p.Target.Mean())*weight
+		p.SquaredError = p.SquaredError*perceptronFadingFactor + diff*diff*weight
+		p.ErrorWeight = p.ErrorWeight*perceptronFadingFactor + weight
+	}
+
+	// Update normalisation stats
+	p.Target.Append(target, weight)
+	for i, predictor := range predictors {
+		if predictor.IsNumeric() {
+			if pv := predictor.Value(inst); !pv.IsMissing() {
+				p.Inputs[i].Append(pv.Value(), weight)
+			}
+		}
+	}
+
+	sd := p.Target.StdDev()
+	if !(sd > 0) {
+		return
+	}
+
+	// Apply the delta rule
+	delta := (target-p.Target.Mean())/(3*sd) - p.predictNormal(inst, predictors)
+	for i, predictor := range predictors {
+		p.Weights[i] += rate * weight * delta * p.normalise(i, predictor, inst)
+	}
+	p.Weights[len(predictors)] += rate * weight * delta
+}
+
+func (p *perceptron) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(p.Weights, p.Inputs, p.Target, p.Error, p.MeanError, p.SquaredError, p.ErrorWeight)
+}
+
+func (p *perceptron) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&p.Weights, &p.Inputs, &p.Target, &p.Error, &p.MeanError, &p.SquaredError, &p.ErrorWeight)
+}
+
+// predictNormal returns the normalised prediction
+func (p *perceptron) predictNormal(inst core.Instance, predictors []*core.Attribute) float64 {
+	sum := p.Weights[len(predictors)]
+	for i, predictor := range predictors {
+		sum += p.Weights[i] * p.normalise(i, predictor, inst)
+	}
+	return sum
+}
+
+// normalise returns the normalised value of the i-th predictor,
+// returns 0 for nominal and missing values
+func (p *perceptron) normalise(i int, predictor *core.Attribute, inst core.Instance) float64 {
+	if !predictor.IsNumeric() {
+		return 0
+	}
+
+	pv := predictor.Value(inst)
+	if pv.IsMissing() {
+		return 0
+	}
+
+	if sd := p.Inputs[i].StdDev(); sd > 0 {
+		return (pv.Value() - p.Inputs[i].Mean()) / (3 * sd)
+	}
+	return 0
+}
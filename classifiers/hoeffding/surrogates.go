@@ -0,0 +1,180 @@
+package hoeffding
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/classifiers/internal/helpers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7770, (*surrogates)(nil))
+	msgpack.Register(7771, (*surrogate)(nil))
+}
+
+// surrogates track how well conditions on other predictors mimic the
+// split condition of a node. Observers monitor the other predictors, using
+// the branch chosen by the node's condition as the target. Periodically,
+// the best split suggestion of each observer is evaluated and surrogates
+// which agree with the node's condition more often than the majority
+// branch are ranked by their agreement. See "Classification and Regression
+// Trees" by Leo Breiman et al. (1984).
+type surrogates struct {
+	Stats            helpers.ObservationStats
+	Observers        []helpers.Observer
+	WeightOnLastEval float64
+	Ranked           []*surrogate
+}
+
+func newSurrogates(tree *Tree, primary string) *surrogates {
+	stats := helpers.NewObservationStats(false)
+	conf := tree.conf.observerConfig()
+
+	predictors := tree.model.Predictors()
+	observers := make([]helpers.Observer, len(predictors))
+	for i, predictor := range predictors {
+		if predictor.Name != primary {
+			observers[i] = stats.NewObserver(predictor.IsNominal(), conf)
+		}
+	}
+	return &surrogates{Stats: stats, Observers: observers}
+}
+
+// Learn observes the branch an instance follows and re-ranks surrogates
+// once a grace period has passed since the last evaluation
+func (s *surrogates) Learn(inst core.Instance, branch int, tree *Tree) {
+	tv := core.AttributeValue(branch)
+	weight := inst.GetInstanceWeight()
+	s.Stats.UpdatePreSplit(tv, weight)
+	observe(s.Observers, inst, tv, weight, tree.model.Predictors())
+
+	if total := s.Stats.TotalWeight(); int(total-s.WeightOnLastEval) >= tree.conf.GracePeriod {
+		s.WeightOnLastEval = total
+		s.rank(tree)
+	}
+}
+
+// Branch returns the branch suggested by the best surrogate
+// which applies to the instance, -1 if none applies
+func (s *surrogates) Branch(inst core.Instance) int {
+	if s == nil {
+		return -1
+	}
+
+	for _, sur := range s.Ranked {
+		if branch, ok := sur.Branches[sur.Condition.Branch(inst)]; ok {
+			return branch
+		}
+	}
+	return -1
+}
+
+func (s *surrogates) ByteSize() int {
+	if s == nil {
+		return 0
+	}
+
+	size := 64 + s.Stats.ByteSize() + len(s.Ranked)*64
+	for _, obs := range s.Observers {
+		if obs != nil {
+			size += obs.ByteSize()
+		}
+	}
+	return size
+}
+
+func (s *surrogates) WriteText(w *bufio.Writer, indent string) error {
+	if s == nil {
+		return nil
+	}
+
+	for _, sur := range s.Ranked {
+		if _, err := fmt.Fprintf(w, "%s~ %s (agreement: %.2f)\n", indent, sur.Condition.Predictor(), sur.Agreement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *surrogates) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(s.Stats, s.Observers, s.WeightOnLastEval, s.Ranked)
+}
+
+func (s *surrogates) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&s.Stats, &s.Observers, &s.WeightOnLastEval, &s.Ranked)
+}
+
+func (s *surrogates) rank(tree *Tree) {
+	total := s.Stats.TotalWeight()
+	if total <= 0 {
+		return
+	}
+
+	// Surrogates must outperform the majority branch
+	state := s.Stats.State()
+	baseline := state.Top().Votes / total
+	state.Release()
+
+	crit := classifiers.DefaultSplitCriterion(false)
+	predictors := tree.model.Predictors()
+
+	ranked := make([]*surrogate, 0, len(s.Observers))
+	for i, obs := range s.Observers {
+		if obs == nil {
+			continue
+		}
+
+		split := s.Stats.BestSplit(crit, obs, predictors[i])
+		if split.Condition() == nil {
+			continue
+		}
+
+		sur := &surrogate{Condition: split.Condition(), Branches: make(map[int]int)}
+		agree, weight := 0.0, 0.0
+		for j, stats := range split.PostStats() {
+			state := stats.State()
+			if top := state.Top(); top.Votes > 0 {
+				sur.Branches[j] = top.Index()
+				agree += top.Votes
+			}
+			weight += stats.TotalWeight()
+			state.Release()
+		}
+
+		if weight > 0 {
+			if sur.Agreement = agree / weight; sur.Agreement > baseline {
+				ranked = append(ranked, sur)
+			}
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Agreement > ranked[j].Agreement
+	})
+	if len(ranked) > tree.conf.NumSurrogates {
+		ranked = ranked[:tree.conf.NumSurrogates]
+	}
+	s.Ranked = ranked
+}
+
+// --------------------------------------------------------------------
+
+// surrogate is a split condition on a different predictor, Branches
+// maps its branches to the branches of the node's split condition
+type surrogate struct {
+	Condition helpers.SplitCondition
+	Branches  map[int]int
+	Agreement float64
+}
+
+func (s *surrogate) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(s.Condition, s.Branches, s.Agreement)
+}
+
+func (s *surrogate) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&s.Condition, &s.Branches, &s.Agreement)
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"io"
 	"math"
+	"math/rand"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/bsm/reason/classifiers/internal/helpers"
 	"github.com/bsm/reason/core"
@@ -28,7 +30,15 @@ type TreeInfo struct {
 	MaxDepth          int
 }
 
-// Tree is an implementation of a HoeffdingTree
+// Tree is an implementation of a HoeffdingTree. Trees are safe for
+// concurrent use: instances are routed to their leaves and learned under a
+// shared lock, so trainings and predictions run in parallel, while splits
+// and prunes take an exclusive lock and block all other calls until the
+// structure of the tree is updated. Adaptive, re-evaluating and surrogate
+// trees (see Config.Adaptive, Config.ReEvalPeriod and Config.NumSurrogates)
+// update inner nodes with every instance and hence train each instance
+// under the exclusive lock, which serialises trainings and blocks
+// predictions while an instance is learned.
 type Tree struct {
 	conf  *Config
 	root  treeNode
@@ -36,8 +46,10 @@ type Tree struct {
 
 	leaves leafNodeSlice
 	cycles int64
+	rnd    *rand.Rand
 
-	mu sync.RWMutex
+	mu    sync.RWMutex
+	rndMu sync.Mutex
 }
 
 // New starts a new hoeffding tree from a model
@@ -69,6 +81,9 @@ func (t *Tree) SetConfig(conf *Config) {
 
 	t.mu.Lock()
 	t.conf = conf
+	if t.rnd == nil {
+		t.rnd = rand.New(rand.NewSource(conf.Seed))
+	}
 	t.mu.Unlock()
 }
 
@@ -125,59 +140,91 @@ func (t *Tree) WriteText(w io.Writer) error {
 	return t.root.WriteText(buf, "\t")
 }
 
-// Train passes an instance to the tree for training purposes
+// Train passes an instance to the tree for training purposes. It is safe
+// to train concurrently, instances are passed to their leaves while
+// holding a shared lock on the tree and an exclusive lock on the leaf.
+// Only structural changes, i.e. splits and pruning, lock the entire tree.
+// Adaptive, re-evaluating and surrogate trees always lock the entire tree.
 func (t *Tree) Train(inst core.Instance) *Trace {
-	var trace *Trace
+	t.mu.RLock()
+	conf := t.conf
+	leaf, ready, ok := t.learn(inst)
+	t.mu.RUnlock()
 
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	var trace *Trace
+	if !ok {
+		t.mu.Lock()
+		t.root, trace = t.train(t.root, inst)
+		t.mu.Unlock()
+	} else if ready {
+		t.mu.Lock()
+		trace = t.splitLeaf(leaf, inst)
+		t.mu.Unlock()
+	}
 
-	node, parent, parentIndex := t.root.Filter(inst, nil, -1)
-	if node == nil {
-		node = newLeafNode(helpers.NewObservationStats(t.model.IsRegression()))
-		parent.Children[parentIndex] = node
+	if conf.PrunePeriod > 0 {
+		if atomic.AddInt64(&t.cycles, 1)%int64(conf.PrunePeriod) == 0 {
+			t.mu.Lock()
+			t.prune()
+			t.mu.Unlock()
+		}
 	}
+	return trace
+}
 
-	if leaf, ok := node.(*leafNode); ok {
-		leaf.Learn(inst, t)
+// Predict returns the raw votes by target index. Predictions block
+// while the tree is updated under an exclusive lock, see Tree.
+func (t *Tree) Predict(inst core.Instance) core.Prediction {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 
-		if t.conf.PrunePeriod > 0 {
-			if t.cycles++; t.cycles%int64(t.conf.PrunePeriod) == 0 {
-				t.prune()
-			}
-		}
+	return t.predict(t.root, inst)
+}
 
-		weight := leaf.Stats.TotalWeight()
-		if int(weight-leaf.WeightOnLastEval) < t.conf.GracePeriod {
-			return trace
-		}
+// TrainBatch passes a batch of instances to the tree for training
+// purposes. Instances are routed to their leaves first, each leaf is then
+// updated with all of its instances and split attempts are made once per
+// leaf at the end of the batch. Results are identical to sequential
+// training unless a leaf becomes ready to split within a batch. In this
+// case, the leaf also learns the remaining instances of the batch, which
+// sequential training would have passed to the new children. Adaptive,
+// re-evaluating and surrogate trees are trained sequentially.
+func (t *Tree) TrainBatch(insts []core.Instance) {
+	t.mu.RLock()
+	conf := t.conf
+	leaves, pending := t.learnBatch(insts)
+	t.mu.RUnlock()
 
-		var split *splitNode
-		if split, trace = t.attemptSplit(leaf, weight, trace); split != nil {
-			if parent == nil {
-				t.root = split
-			} else {
-				parent.SetChild(parentIndex, split)
-			}
+	if len(leaves) != 0 || len(pending) != 0 {
+		t.mu.Lock()
+		for _, inst := range pending {
+			t.root, _ = t.train(t.root, inst)
+		}
+		for _, leaf := range leaves {
+			t.splitLeaf(leaf.node, leaf.inst)
 		}
+		t.mu.Unlock()
+	}
 
-		if weight > leaf.WeightOnLastEval {
-			leaf.WeightOnLastEval = weight
+	if period := int64(conf.PrunePeriod); period > 0 {
+		if n := atomic.AddInt64(&t.cycles, int64(len(insts))); n/period > (n-int64(len(insts)))/period {
+			t.mu.Lock()
+			t.prune()
+			t.mu.Unlock()
 		}
 	}
-	return trace
 }
 
-// Predict returns the raw votes by target index
-func (t *Tree) Predict(inst core.Instance) core.Prediction {
+// PredictBatch returns the predictions for a batch of instances
+func (t *Tree) PredictBatch(insts []core.Instance) []core.Prediction {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	node, parent, _ := t.root.Filter(inst, nil, -1)
-	if node == nil {
-		node = parent
+	res := make([]core.Prediction, len(insts))
+	for i, inst := range insts {
+		res[i] = t.predict(t.root, inst)
 	}
-	return node.Predict()
+	return res
 }
 
 // DumpTo writes the tree to a writer
@@ -185,6 +232,9 @@ func (t *Tree) DumpTo(w io.Writer) error {
 	enc := msgpack.NewEncoder(w)
 	defer enc.Close()
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	return enc.Encode(t)
 }
 
@@ -204,6 +254,288 @@ func (t *Tree) DecodeFrom(dec *msgpack.Decoder) error {
 	return dec.Decode(&t.model, &t.root)
 }
 
+// train trains the subtree at root with an instance and returns the
+// (potentially replaced) root node
+func (t *Tree) train(root treeNode, inst core.Instance) (treeNode, *Trace) {
+	var trace *Trace
+
+	if t.conf.Adaptive || t.conf.ReEvalPeriod > 0 || t.conf.NumSurrogates > 0 {
+		var done bool
+		if root, done = t.walk(root, inst); done {
+			return root, trace
+		}
+	}
+
+	node, parent, parentIndex := root.Filter(inst, t.conf.MissingStrategy, nil, -1)
+	if node == nil {
+		node = newLeafNode(helpers.NewObservationStats(t.model.IsRegression()))
+		parent.Children[parentIndex] = node
+	}
+
+	leaf, ok := node.(*leafNode)
+	if !ok {
+		if split, ok := node.(*splitNode); ok && t.conf.MissingStrategy == MissingStrategyFractional {
+			t.trainFractional(split, inst)
+		}
+		return root, trace
+	}
+	leaf.Learn(inst, t)
+
+	return t.split(root, leaf, parent, parentIndex)
+}
+
+// learn passes an instance to its leaf, while holding a shared lock on
+// the tree. It returns the leaf and true if the leaf is ready for a split
+// attempt. It returns false as the last argument if the instance
+// requires an exclusive lock on the tree.
+func (t *Tree) learn(inst core.Instance) (*leafNode, bool, bool) {
+	if t.conf.Adaptive || t.conf.ReEvalPeriod > 0 || t.conf.NumSurrogates > 0 {
+		return nil, false, false
+	}
+
+	node, _, _ := t.root.Filter(inst, t.conf.MissingStrategy, nil, -1)
+	if node == nil {
+		return nil, false, false
+	}
+
+	leaf, ok := node.(*leafNode)
+	if !ok {
+		// Fractional instances require an exclusive lock
+		return nil, false, t.conf.MissingStrategy != MissingStrategyFractional
+	}
+
+	leaf.mu.Lock()
+	defer leaf.mu.Unlock()
+
+	leaf.Learn(inst, t)
+	return leaf, int(leaf.Stats.TotalWeight()-leaf.WeightOnLastEval) >= t.conf.GracePeriod, true
+}
+
+// batchLeaf is a leaf which is ready for a split attempt
+// and an instance which has reached it
+type batchLeaf struct {
+	node *leafNode
+	inst core.Instance
+}
+
+// learnBatch passes a batch of instances to their leaves, while holding
+// a shared lock on the tree. It returns the leaves which are ready for
+// split attempts and the instances which require an exclusive lock.
+func (t *Tree) learnBatch(insts []core.Instance) ([]batchLeaf, []core.Instance) {
+	if t.conf.Adaptive || t.conf.ReEvalPeriod > 0 || t.conf.NumSurrogates > 0 {
+		return nil, insts
+	}
+
+	// Route instances to leaves
+	var pending []core.Instance
+	var order []*leafNode
+	routes := make(map[*leafNode][]core.Instance)
+	for _, inst := range insts {
+		node, _, _ := t.root.Filter(inst, t.conf.MissingStrategy, nil, -1)
+		if node == nil {
+			pending = append(pending, inst)
+			continue
+		}
+
+		leaf, ok := node.(*leafNode)
+		if !ok {
+			if t.conf.MissingStrategy == MissingStrategyFractional {
+				pending = append(pending, inst)
+			}
+			continue
+		}
+		if _, ok := routes[leaf]; !ok {
+			order = append(order, leaf)
+		}
+		routes[leaf] = append(routes[leaf], inst)
+	}
+
+	// Update leaves
+	var ready []batchLeaf
+	for _, leaf := range order {
+		leafInsts := routes[leaf]
+
+		leaf.mu.Lock()
+		for _, inst := range leafInsts {
+			leaf.Learn(inst, t)
+		}
+		if int(leaf.Stats.TotalWeight()-leaf.WeightOnLastEval) >= t.conf.GracePeriod {
+			ready = append(ready, batchLeaf{node: leaf, inst: leafInsts[0]})
+		}
+		leaf.mu.Unlock()
+	}
+	return ready, pending
+}
+
+// splitLeaf attempts to split a leaf, the instance is used to locate the
+// leaf's parent. Requires an exclusive lock on the tree.
+func (t *Tree) splitLeaf(leaf *leafNode, inst core.Instance) (trace *Trace) {
+	node, parent, parentIndex := t.root.Filter(inst, t.conf.MissingStrategy, nil, -1)
+	if node != leaf {
+		return // leaf was split in the meantime
+	}
+
+	t.root, trace = t.split(t.root, leaf, parent, parentIndex)
+	return
+}
+
+// split attempts to split a leaf, once it has observed enough instances
+// since the last attempt, and returns the (potentially replaced) root node
+func (t *Tree) split(root treeNode, leaf *leafNode, parent *splitNode, parentIndex int) (treeNode, *Trace) {
+	weight := leaf.Stats.TotalWeight()
+	if int(weight-leaf.WeightOnLastEval) < t.conf.GracePeriod {
+		return root, nil
+	}
+
+	split, trace := t.attemptSplit(leaf, weight, nil)
+	if split != nil {
+		if parent == nil {
+			root = split
+		} else {
+			parent.SetChild(parentIndex, split)
+		}
+	}
+
+	if weight > leaf.WeightOnLastEval {
+		leaf.WeightOnLastEval = weight
+	}
+	return root, trace
+}
+
+// trainFractional trains all branches of a split node with an instance,
+// weighted by the relative weight of each branch
+func (t *Tree) trainFractional(split *splitNode, inst core.Instance) {
+	weight := inst.GetInstanceWeight()
+	branches, fractions := split.fractions()
+	for i, branch := range branches {
+		split.Children[branch], _ = t.train(split.Children[branch], helpers.WeightedInstance{
+			Instance: inst,
+			Weight:   weight * fractions[i],
+		})
+	}
+}
+
+// predict filters an instance through the subtree at root and returns
+// the prediction of the node it reaches
+func (t *Tree) predict(root treeNode, inst core.Instance) core.Prediction {
+	node, parent, _ := root.Filter(inst, t.conf.MissingStrategy, nil, -1)
+	if node == nil {
+		return parent.Predict()
+	}
+
+	switch n := node.(type) {
+	case *leafNode:
+		return n.PredictInstance(inst, t)
+	case *splitNode:
+		if t.conf.MissingStrategy == MissingStrategyFractional {
+			return t.predictFractional(n, inst)
+		}
+	}
+	return node.Predict()
+}
+
+// predictFractional combines the predictions of all branches of a split
+// node, weighted by the relative weight of each branch
+func (t *Tree) predictFractional(split *splitNode, inst core.Instance) core.Prediction {
+	branches, fractions := split.fractions()
+	if len(branches) == 0 {
+		return split.Predict()
+	}
+
+	predictions := make([]core.Prediction, len(branches))
+	for i, branch := range branches {
+		predictions[i] = t.predict(split.Children[branch], inst)
+	}
+
+	res := combinePredictions(t.model.IsRegression(), predictions, fractions)
+	for _, p := range predictions {
+		p.Release()
+	}
+	return res
+}
+
+// newObservers creates observers for all predictors or, if a subspace
+// is configured, for a random subset of predictors
+func (t *Tree) newObservers(stats helpers.ObservationStats) []helpers.Observer {
+	predictors := t.model.Predictors()
+	observers := make([]helpers.Observer, len(predictors))
+	conf := t.conf.observerConfig()
+
+	if n := t.conf.Subspace; n > 0 && n < len(predictors) {
+		t.rndMu.Lock()
+		perm := t.rnd.Perm(len(predictors))
+		t.rndMu.Unlock()
+
+		for _, i := range perm[:n] {
+			observers[i] = stats.NewObserver(predictors[i].IsNominal(), conf)
+		}
+		return observers
+	}
+
+	for i, predictor := range predictors {
+		observers[i] = stats.NewObserver(predictor.IsNominal(), conf)
+	}
+	return observers
+}
+
+// walk passes an instance along the path of split nodes. Depending on the
+// configuration, split nodes update their error estimates and switch in
+// alternate subtrees (HAT), learn their surrogates or learn from the
+// instance and re-evaluate their split conditions (EFDT). It returns the
+// (potentially replaced) root node and true if the instance has been fully
+// consumed.
+func (t *Tree) walk(root treeNode, inst core.Instance) (treeNode, bool) {
+	tv := t.model.Target().Value(inst)
+	if tv.IsMissing() {
+		return root, false
+	}
+
+	var parent *splitNode
+	var parentIndex int
+
+	replace := func(node treeNode) {
+		if parent == nil {
+			root = node
+		} else {
+			parent.SetChild(parentIndex, node)
+		}
+	}
+
+	for node := root; ; {
+		split, ok := node.(*splitNode)
+		if !ok {
+			return root, false
+		}
+
+		if t.conf.Adaptive {
+			if alt := split.Adapt(inst, tv, t); alt != nil {
+				replace(alt)
+				node = alt
+				continue
+			}
+		}
+
+		if t.conf.NumSurrogates > 0 {
+			split.LearnSurrogates(inst, t)
+		}
+
+		if t.conf.ReEvalPeriod > 0 {
+			split.Learn(inst, t)
+			if repl := t.reEvaluate(split); repl != nil {
+				replace(repl)
+				return root, true
+			}
+		}
+
+		branch := split.branch(inst, t.conf.MissingStrategy)
+		child, ok := split.Children[branch]
+		if !ok {
+			return root, false
+		}
+		parent, parentIndex, node = split, branch, child
+	}
+}
+
 func (t *Tree) attemptSplit(leaf *leafNode, weight float64, trace *Trace) (*splitNode, *Trace) {
 	if !leaf.Stats.IsSufficient() || leaf.IsInactive {
 		return nil, nil
@@ -218,8 +550,9 @@ func (t *Tree) attemptSplit(leaf *leafNode, weight float64, trace *Trace) (*spli
 	bestSplit := splits[0]
 
 	// Calculate the gain between merits of the best and the second-best split
+	// or, in EFDT mode, the gain over the null split
 	meritGain := bestSplit.Merit()
-	if len(splits) > 1 {
+	if len(splits) > 1 && t.conf.ReEvalPeriod == 0 {
 		meritGain -= splits[1].Merit()
 	}
 
@@ -244,8 +577,7 @@ func (t *Tree) attemptSplit(leaf *leafNode, weight float64, trace *Trace) (*spli
 	}
 
 	// Calculate hoeffding bound, evaluate split
-	srange := bestSplit.Range()
-	hbound := math.Sqrt(srange * srange * math.Log(1.0/t.conf.SplitConfidence) / (2.0 * weight))
+	hbound := t.hoeffdingBound(bestSplit.Range(), weight)
 
 	// Update trace
 	if trace != nil {
@@ -258,15 +590,70 @@ func (t *Tree) attemptSplit(leaf *leafNode, weight float64, trace *Trace) (*spli
 			trace.Split = true
 		}
 
-		return newSplitNode(
+		split := newSplitNode(
 			bestSplit.Condition(),
 			bestSplit.PreStats(),
 			bestSplit.PostStats(),
-		), nil
+		)
+		if t.conf.ReEvalPeriod > 0 {
+			split.Observers = leaf.Observers
+			split.WeightOnLastEval = weight
+		}
+		if leaf.Perceptron != nil {
+			for _, child := range split.Children {
+				child.(*leafNode).Perceptron = leaf.Perceptron.Clone()
+			}
+		}
+		return split, nil
 	}
 	return nil, nil
 }
 
+// reEvaluate re-evaluates the condition of a split node and returns a
+// replacement if a different predictor has become the better choice
+func (t *Tree) reEvaluate(split *splitNode) *splitNode {
+	weight := split.Stats.TotalWeight()
+	if int(weight-split.WeightOnLastEval) < t.conf.ReEvalPeriod || !split.Stats.IsSufficient() {
+		return nil
+	}
+	split.WeightOnLastEval = weight
+
+	// Calculate best splits, skip if current predictor is still the best
+	current := split.Condition.Predictor()
+	splits := bestSplits(split.Stats, split.Observers, t)
+	bestSplit := splits[0]
+	if cond := bestSplit.Condition(); cond == nil || cond.Predictor() == current {
+		return nil
+	}
+
+	// Calculate the gain between merits of the best and the current split
+	meritGain := bestSplit.Merit()
+	for _, s := range splits[1:] {
+		if cond := s.Condition(); cond != nil && cond.Predictor() == current {
+			meritGain -= s.Merit()
+			break
+		}
+	}
+
+	// Calculate hoeffding bound, evaluate replacement
+	hbound := t.hoeffdingBound(bestSplit.Range(), weight)
+	if meritGain > hbound || (hbound < t.conf.TieThreshold && meritGain > t.conf.TieThreshold/2) {
+		repl := newSplitNode(
+			bestSplit.Condition(),
+			bestSplit.PreStats(),
+			bestSplit.PostStats(),
+		)
+		repl.Observers = split.Observers
+		repl.WeightOnLastEval = weight
+		return repl
+	}
+	return nil
+}
+
+func (t *Tree) hoeffdingBound(srange, weight float64) float64 {
+	return math.Sqrt(srange * srange * math.Log(1.0/t.conf.SplitConfidence) / (2.0 * weight))
+}
+
 func (t *Tree) prune() {
 	byteSize := t.root.ByteSize()
 	if byteSize <= t.conf.PruneMemTarget {
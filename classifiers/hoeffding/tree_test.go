@@ -1,8 +1,12 @@
 package hoeffding
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/bsm/reason/core"
@@ -65,6 +69,56 @@ var _ = Describe("Tree", func() {
 		testDumpLoad("../../testdata/bigreg.csv", model)
 	})
 
+	It("should load unversioned dumps", func() {
+		load := func(fname string) *Tree {
+			file, err := os.Open(fname)
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			tree, err := Load(file, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return tree
+		}
+		probes := []core.MapInstance{
+			{"x": 1.0, "c": "u"},
+			{"x": 7.5, "c": "u"},
+			{"x": 1.0, "c": "w"},
+			{"x": 7.5, "c": "w"},
+		}
+
+		cls := load("testdata/unversioned-classification.dump")
+		Expect(cls.Info()).To(Equal(&TreeInfo{NumNodes: 33, NumActiveLeaves: 6, NumInactiveLeaves: 12, MaxDepth: 6}))
+		for i, exp := range []core.PredictedValue{
+			{AttributeValue: 1, Votes: 131.064},
+			{AttributeValue: 0, Votes: 283},
+			{AttributeValue: 0, Votes: 196},
+			{AttributeValue: 1, Votes: 187.130},
+		} {
+			top := cls.Predict(probes[i]).Top()
+			Expect(top.AttributeValue).To(Equal(exp.AttributeValue), "probe %d", i)
+			Expect(top.Votes).To(BeNumerically("~", exp.Votes, 0.001), "probe %d", i)
+		}
+
+		reg := load("testdata/unversioned-regression.dump")
+		Expect(reg.Info()).To(Equal(&TreeInfo{NumNodes: 51, NumActiveLeaves: 27, NumInactiveLeaves: 0, MaxDepth: 6}))
+		for i, exp := range []float64{0.616, 17.632, 10.740, 27.199} {
+			Expect(reg.Predict(probes[i]).Value()).To(BeNumerically("~", exp, 0.001), "probe %d", i)
+		}
+
+		// loaded trees continue to learn and are dumped in the current format
+		for i := 0; i < 100; i++ {
+			cls.Train(core.MapInstance{"x": float64(i % 10), "c": "v", "class": "b"})
+			reg.Train(core.MapInstance{"x": float64(i % 10), "c": "v", "y": 7.0})
+		}
+		for _, tree := range []*Tree{cls, reg} {
+			buf := new(bytes.Buffer)
+			Expect(tree.DumpTo(buf)).To(Succeed())
+			tree2, err := Load(buf, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree2.root).To(Equal(tree.root))
+		}
+	})
+
 	It("should prune", func() {
 		model := testdata.BigClassificationModel()
 		tree := trainTree("../../testdata/bigcls.csv", model)
@@ -85,6 +139,641 @@ var _ = Describe("Tree", func() {
 		}))
 	})
 
+	It("should adapt to concept drift", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3", "v4")},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2")},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		values := []string{"v1", "v2", "v3", "v4"}
+		generate := func(n int, drifted bool) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				x, y := rnd.Intn(4), rnd.Intn(2)
+				class := "a"
+				if (x < 2) != drifted {
+					class = "b"
+				}
+				insts = append(insts, core.MapInstance{
+					"x":     values[x],
+					"y":     values[y],
+					"class": class,
+				})
+			}
+			return insts
+		}
+
+		train := append(generate(4000, false), generate(3000, true)...)
+		test := generate(1000, true)
+		run := func(conf *Config) (*Tree, float64) {
+			tree := New(model, conf)
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+
+			stats := eval.NewClassification(model)
+			for _, inst := range test {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			return tree, stats.Correct()
+		}
+
+		_, correct := run(&Config{GracePeriod: 50})
+		Expect(correct).To(BeNumerically("<", 0.5))
+
+		tree, correct := run(&Config{GracePeriod: 50, Adaptive: true})
+		Expect(correct).To(BeNumerically(">", 0.99))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+	})
+
+	It("should re-evaluate splits", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2")},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2")},
+		)
+
+		// x looks perfect initially, but y is the better predictor long-term
+		rnd := rand.New(rand.NewSource(1))
+		values, classes := []string{"v1", "v2"}, []string{"a", "b"}
+		insts := make([]core.Instance, 0, 5000)
+		for i := 0; i < cap(insts); i++ {
+			x, y := rnd.Intn(2), rnd.Intn(2)
+			class := y
+			if i < 100 {
+				class = x
+			} else if rnd.Intn(10) == 0 {
+				class = 1 - y
+			}
+			insts = append(insts, core.MapInstance{"x": values[x], "y": values[y], "class": classes[class]})
+		}
+
+		run := func(conf *Config) *Tree {
+			tree := New(model, conf)
+			for _, inst := range insts {
+				tree.Train(inst)
+			}
+			return tree
+		}
+
+		tree := run(&Config{GracePeriod: 50, SplitConfidence: 0.01})
+		Expect(tree.root.(*splitNode).Condition.Predictor()).To(Equal("x"))
+
+		tree = run(&Config{GracePeriod: 50, SplitConfidence: 0.01, ReEvalPeriod: 200})
+		Expect(tree.root.(*splitNode).Condition.Predictor()).To(Equal("y"))
+		Expect(tree.root.(*splitNode).Observers).To(HaveLen(2))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+	})
+
+	DescribeTable("should train concurrently", func(conf *Config) {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3", "v4")},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3", "v4")},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		values := []string{"v1", "v2", "v3", "v4"}
+		insts := make([]core.Instance, 0, 8000)
+		for i := 0; i < cap(insts); i++ {
+			x, y := rnd.Intn(4), rnd.Intn(4)
+			class := "a"
+			if x+y > 3 {
+				class = "b"
+			}
+			insts = append(insts, core.MapInstance{"x": values[x], "y": values[y], "class": class})
+		}
+
+		tree := New(model, conf)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(part []core.Instance) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				for _, inst := range part {
+					tree.Train(inst)
+					tree.Predict(inst).Release()
+				}
+			}(insts[i*1000 : (i+1)*1000])
+		}
+		wg.Wait()
+
+		Expect(tree.Info().NumNodes).To(BeNumerically(">", 1))
+
+		stats := eval.NewClassification(model)
+		for _, inst := range insts[:1000] {
+			stats.Record(inst, tree.Predict(inst))
+		}
+		Expect(stats.Correct()).To(BeNumerically(">", 0.9))
+	},
+		Entry("default", &Config{GracePeriod: 50}),
+		Entry("adaptive with surrogates", &Config{GracePeriod: 50, Adaptive: true, NumSurrogates: 2}),
+	)
+
+	It("should train/predict in batches", func() {
+		model := testdata.ClassificationModel()
+		insts := testdata.ClassificationData()
+
+		// no splits within the batch
+		tree1 := New(model, nil)
+		for _, inst := range insts {
+			tree1.Train(inst)
+		}
+		tree2 := New(model, nil)
+		tree2.TrainBatch(insts)
+		Expect(tree2.root).To(Equal(tree1.root))
+
+		predictions := tree2.PredictBatch(insts)
+		Expect(predictions).To(HaveLen(len(insts)))
+		for i, inst := range insts {
+			Expect(predictions[i]).To(Equal(tree1.Predict(inst)))
+		}
+
+		// splits within batches
+		model = testdata.BigRegressionModel()
+		stream, err := testdata.Open("../../testdata/bigreg.csv", model)
+		Expect(err).NotTo(HaveOccurred())
+		defer stream.Close()
+
+		insts, err = stream.ReadN(6000)
+		Expect(err).NotTo(HaveOccurred())
+
+		tree1 = New(model, &Config{GracePeriod: 50})
+		tree2 = New(model, &Config{GracePeriod: 50})
+		for i := 0; i < 5000; i += 100 {
+			for _, inst := range insts[i : i+100] {
+				tree1.Train(inst)
+			}
+			tree2.TrainBatch(insts[i : i+100])
+		}
+
+		stats1, stats2 := eval.NewRegression(model), eval.NewRegression(model)
+		for i, p := range tree2.PredictBatch(insts[5000:]) {
+			stats1.Record(insts[5000+i], tree1.Predict(insts[5000+i]))
+			stats2.Record(insts[5000+i], p)
+		}
+		Expect(stats2.RMSE()).To(BeNumerically("~", stats1.RMSE(), 0.05))
+		Expect(tree2.Info().NumNodes).To(BeNumerically("~", tree1.Info().NumNodes, tree1.Info().NumNodes/10))
+	})
+
+	It("should predict with naive bayes leaves", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x1", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x2", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x3", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x4", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x5", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+		)
+
+		// each predictor agrees with the class 70% of the time,
+		// independently of all others
+		rnd := rand.New(rand.NewSource(1))
+		values := []string{"a", "b"}
+		generate := func(n int) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				class := rnd.Intn(2)
+				inst := core.MapInstance{"class": values[class]}
+				for _, name := range []string{"x1", "x2", "x3", "x4", "x5"} {
+					if rnd.Float64() < 0.7 {
+						inst[name] = values[class]
+					} else {
+						inst[name] = values[1-class]
+					}
+				}
+				insts = append(insts, inst)
+			}
+			return insts
+		}
+
+		train, test := generate(2000), generate(1000)
+		run := func(conf *Config) float64 {
+			tree := New(model, conf)
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+
+			stats := eval.NewClassification(model)
+			for _, inst := range test {
+				prediction := tree.Predict(inst)
+				stats.Record(inst, prediction)
+				prediction.Release()
+			}
+			return stats.Correct()
+		}
+
+		Expect(run(nil)).To(BeNumerically("<", 0.7))
+		Expect(run(&Config{LeafPrediction: LeafPredictionNaiveBayes})).To(BeNumerically(">", 0.8))
+		Expect(run(&Config{LeafPrediction: LeafPredictionNaiveBayesAdaptive})).To(BeNumerically(">", 0.8))
+	})
+
+	It("should predict with perceptron leaves", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "c", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		values := []string{"a", "b"}
+		generate := func(n int) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				x, c := rnd.Float64()*10, rnd.Intn(2)
+				insts = append(insts, core.MapInstance{
+					"x": x,
+					"c": values[c],
+					"y": 3*x + float64(c)*10 + rnd.NormFloat64(),
+				})
+			}
+			return insts
+		}
+
+		train, test := generate(3000), generate(1000)
+		run := func(conf *Config) (*Tree, float64) {
+			tree := New(model, conf)
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+
+			stats := eval.NewRegression(model)
+			for _, inst := range test {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			return tree, stats.RMSE()
+		}
+
+		_, rmse := run(nil)
+		Expect(rmse).To(BeNumerically(">", 1.8))
+
+		_, rmse = run(&Config{LeafPrediction: LeafPredictionPerceptronAdaptive})
+		Expect(rmse).To(BeNumerically("<", 1.4))
+
+		tree, rmse := run(&Config{LeafPrediction: LeafPredictionPerceptron})
+		Expect(rmse).To(BeNumerically("<", 1.4))
+
+		prediction := tree.Predict(test[0])
+		Expect(prediction.Value()).To(BeNumerically("~", test[0].(core.MapInstance)["y"], 3))
+		Expect(prediction.Top().Variance).To(BeNumerically("~", 2.0, 1.0))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+	})
+
+	It("should adapt regressions to concept drift", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		generate := func(n int, drifted bool) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				x := rnd.Float64() * 10
+				y := 3 * x
+				if drifted {
+					y = 30 - y
+				}
+				insts = append(insts, core.MapInstance{"x": x, "y": y + rnd.NormFloat64()})
+			}
+			return insts
+		}
+
+		train := append(generate(5000, false), generate(5000, true)...)
+		test := generate(1000, true)
+		run := func(conf *Config) (*Tree, float64) {
+			tree := New(model, conf)
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+
+			stats := eval.NewRegression(model)
+			for _, inst := range test {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			return tree, stats.RMSE()
+		}
+
+		_, rmse := run(&Config{GracePeriod: 50})
+		Expect(rmse).To(BeNumerically(">", 5.0))
+
+		tree, rmse := run(&Config{GracePeriod: 50, Adaptive: true})
+		Expect(rmse).To(BeNumerically("<", 1.5))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+	})
+
+	DescribeTable("should support numeric observers",
+		func(obs NumericObserver) {
+			model := core.NewModel(
+				&core.Attribute{Name: "y", Kind: core.AttributeKindNumeric},
+				&core.Attribute{Name: "x", Kind: core.AttributeKindNumeric},
+			)
+
+			rnd := rand.New(rand.NewSource(1))
+			generate := func(n int) []core.Instance {
+				insts := make([]core.Instance, 0, n)
+				for i := 0; i < n; i++ {
+					x := rnd.Float64() * 10
+					insts = append(insts, core.MapInstance{"x": x, "y": 3*x + rnd.NormFloat64()})
+				}
+				return insts
+			}
+
+			tree := New(model, &Config{NumericObserver: obs, NumBins: 20, GracePeriod: 50})
+			for _, inst := range generate(3000) {
+				tree.Train(inst)
+			}
+
+			stats := eval.NewRegression(model)
+			for _, inst := range generate(1000) {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			Expect(tree.Info().NumNodes).To(BeNumerically(">", 1))
+			Expect(stats.RMSE()).To(BeNumerically("<", 1.2))
+
+			buf := new(bytes.Buffer)
+			Expect(tree.DumpTo(buf)).To(Succeed())
+			tree2, err := Load(buf, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree2.root).To(Equal(tree.root))
+		},
+
+		Entry("gaussian", NumericObserverGaussian),
+		Entry("E-BST", NumericObserverEBST),
+		Entry("sketch", NumericObserverSketch),
+	)
+
+	It("should support binary nominal splits", func() {
+		values := make([]string, 8)
+		for i := range values {
+			values[i] = fmt.Sprintf("v%d", i+1)
+		}
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "country", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues(values...)},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		insts := make([]core.Instance, 0, 2000)
+		for i := 0; i < cap(insts); i++ {
+			x := rnd.Intn(len(values))
+			class := "a"
+			if x == 2 || x == 7 {
+				class = "b"
+			}
+			insts = append(insts, core.MapInstance{"country": values[x], "class": class})
+		}
+
+		run := func(conf *Config) *Tree {
+			tree := New(model, conf)
+			for _, inst := range insts {
+				tree.Train(inst)
+			}
+			return tree
+		}
+
+		tree := run(&Config{GracePeriod: 50})
+		Expect(tree.root.(*splitNode).Children).To(HaveLen(8))
+
+		tree = run(&Config{GracePeriod: 50, NominalSplit: NominalSplitBinary})
+		Expect(tree.root.(*splitNode).Children).To(HaveLen(2))
+		Expect(tree.Info().NumNodes).To(Equal(5))
+
+		tree = run(&Config{GracePeriod: 50, NominalSplit: NominalSplitSubset})
+		Expect(tree.root.(*splitNode).Children).To(HaveLen(2))
+		Expect(tree.Info().NumNodes).To(Equal(3))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+
+		buf.Reset()
+		Expect(tree.WriteText(buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`country "in {v3, v8}"`))
+		Expect(buf.String()).To(ContainSubstring(`country "not in {v3, v8}"`))
+	})
+
+	It("should support k-interval numeric splits", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "hour", Kind: core.AttributeKindNumeric},
+		)
+
+		rnd := rand.New(rand.NewSource(1))
+		insts := make([]core.Instance, 0, 2000)
+		for i := 0; i < cap(insts); i++ {
+			x := float64(rnd.Intn(24))
+			class := "a"
+			if x > 8 && x < 18 {
+				class = "b"
+			}
+			insts = append(insts, core.MapInstance{"hour": x, "class": class})
+		}
+
+		run := func(conf *Config) *Tree {
+			tree := New(model, conf)
+			for _, inst := range insts {
+				tree.Train(inst)
+			}
+			return tree
+		}
+
+		tree := run(&Config{GracePeriod: 50, NumericObserver: NumericObserverEBST})
+		Expect(tree.root.(*splitNode).Children).To(HaveLen(2))
+
+		tree = run(&Config{GracePeriod: 50, NumericObserver: NumericObserverEBST, NumIntervals: 3})
+		Expect(tree.root.(*splitNode).Children).To(HaveLen(3))
+		Expect(tree.Info().NumNodes).To(Equal(4))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+
+		buf.Reset()
+		Expect(tree.WriteText(buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`hour "<= 8.000000"`))
+		Expect(buf.String()).To(ContainSubstring(`hour "> 8.000000, <= 17.000000"`))
+		Expect(buf.String()).To(ContainSubstring(`hour "> 17.000000"`))
+	})
+
+	It("should handle missing values", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3", "v4")},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2")},
+		)
+
+		// x is missing in 30% of all instances, the class then mostly depends on y
+		rnd := rand.New(rand.NewSource(1))
+		values, classes := []string{"v1", "v2", "v3", "v4"}, []string{"a", "b"}
+		generate := func(n int, missing bool) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				x, y := rnd.Intn(4), rnd.Intn(2)
+				if missing || rnd.Float64() < 0.3 {
+					class := y
+					if rnd.Intn(10) == 0 {
+						class = 1 - y
+					}
+					insts = append(insts, core.MapInstance{"y": values[y], "class": classes[class]})
+				} else {
+					insts = append(insts, core.MapInstance{"x": values[x], "y": values[y], "class": classes[x/2]})
+				}
+			}
+			return insts
+		}
+
+		train, test := generate(4000, false), generate(1000, true)
+		run := func(strategy MissingStrategy) (*Tree, float64, float64) {
+			tree := New(model, &Config{GracePeriod: 50, MissingStrategy: strategy})
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+			Expect(tree.root.(*splitNode).Condition.Predictor()).To(Equal("x"))
+
+			weight := 0.0
+			for _, leaf := range tree.root.FindLeaves(nil) {
+				weight += leaf.TotalWeight()
+			}
+
+			stats := eval.NewClassification(model)
+			for _, inst := range test {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			return tree, weight, stats.Correct()
+		}
+
+		_, weight, correct := run(MissingStrategyStop)
+		Expect(weight).To(BeNumerically("<", 3000))
+		Expect(correct).To(BeNumerically("~", 0.5, 0.05))
+
+		tree, weight, _ := run(MissingStrategyMajority)
+		Expect(weight).To(BeNumerically(">", 3900))
+		root := tree.root.(*splitNode)
+		branches, fractions := root.fractions()
+		heaviest := 0
+		for i := range fractions {
+			if fractions[i] > fractions[heaviest] {
+				heaviest = i
+			}
+		}
+		Expect(root.branch(test[0], MissingStrategyMajority)).To(Equal(branches[heaviest]))
+		Expect(tree.Predict(test[0])).To(Equal(tree.predict(root.Children[branches[heaviest]], test[0])))
+
+		tree, weight, _ = run(MissingStrategyFractional)
+		Expect(weight).To(BeNumerically("~", 4000, 100))
+		Expect(tree.Predict(test[0])).To(HaveLen(2))
+
+		tree, weight, correct = run(MissingStrategyBranch)
+		Expect(weight).To(BeNumerically(">", 3900))
+		Expect(correct).To(BeNumerically(">", 0.85))
+		Expect(tree.root.(*splitNode).Children).To(HaveKey(missingBranch))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+
+		buf.Reset()
+		Expect(tree.WriteText(buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`x "is missing" -> 0.00`))
+
+		buf.Reset()
+		Expect(tree.WriteGraph(buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`N -> N_m [label="is missing"];`))
+	})
+
+	It("should use surrogate splits", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+			&core.Attribute{Name: "x", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2", "v3", "v4")},
+			&core.Attribute{Name: "y", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("v1", "v2")},
+			&core.Attribute{Name: "z", Kind: core.AttributeKindNumeric},
+		)
+
+		// class depends on x, z is a noisy proxy of x
+		rnd := rand.New(rand.NewSource(1))
+		values, classes := []string{"v1", "v2", "v3", "v4"}, []string{"a", "b"}
+		generate := func(n int, missing bool) []core.Instance {
+			insts := make([]core.Instance, 0, n)
+			for i := 0; i < n; i++ {
+				x, y := rnd.Intn(4), rnd.Intn(2)
+				z := float64(x/2)*10 + rnd.NormFloat64()*3
+				inst := core.MapInstance{"y": values[y], "z": z, "class": classes[x/2]}
+				if !missing {
+					inst["x"] = values[x]
+				}
+				insts = append(insts, inst)
+			}
+			return insts
+		}
+
+		train, test := generate(4000, false), generate(1000, true)
+		run := func(conf *Config) (*Tree, float64) {
+			tree := New(model, conf)
+			for _, inst := range train {
+				tree.Train(inst)
+			}
+			Expect(tree.root.(*splitNode).Condition.Predictor()).To(Equal("x"))
+
+			stats := eval.NewClassification(model)
+			for _, inst := range test {
+				stats.Record(inst, tree.Predict(inst))
+			}
+			return tree, stats.Correct()
+		}
+
+		_, correct := run(&Config{GracePeriod: 50})
+		Expect(correct).To(BeNumerically("~", 0.5, 0.05))
+
+		tree, correct := run(&Config{GracePeriod: 50, NumSurrogates: 2})
+		Expect(correct).To(BeNumerically(">", 0.9))
+
+		root := tree.root.(*splitNode)
+		Expect(root.Surrogates.Ranked).NotTo(BeEmpty())
+		Expect(root.Surrogates.Ranked[0].Condition.Predictor()).To(Equal("z"))
+		Expect(root.Surrogates.Ranked[0].Agreement).To(BeNumerically(">", 0.4))
+
+		buf := new(bytes.Buffer)
+		Expect(tree.DumpTo(buf)).To(Succeed())
+		tree2, err := Load(buf, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree2.root).To(Equal(tree.root))
+
+		buf.Reset()
+		Expect(tree.WriteText(buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("\t~ z (agreement: 0.4"))
+	})
+
 	DescribeTable("should perform classification",
 		func(n int, expInfo *TreeInfo, expCorrect, expKappa float64) {
 			if testing.Short() && n > 1000 {
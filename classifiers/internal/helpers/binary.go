@@ -0,0 +1,202 @@
+package helpers
+
+import (
+	"sort"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+)
+
+func init() {
+	msgpack.Register(7767, (*nominalBinaryCObserver)(nil))
+	msgpack.Register(7768, (*nominalBinaryRObserver)(nil))
+}
+
+// NewNominalBinaryCObserver monitors a nominal predictor attribute and
+// suggests binary splits which separate a single value from the rest.
+// If subset is true and the target is binary, values are ordered by the
+// proportion of the first class and the best subset of values is separated
+// from the rest instead. See "Classification and Regression Trees" by Leo
+// Breiman et al. (1984).
+func NewNominalBinaryCObserver(subset bool) CObserver {
+	return &nominalBinaryCObserver{
+		nominalCObserver: nominalCObserver{PostSplit: util.NewVectorDistribution()},
+		Subset:           subset,
+	}
+}
+
+type nominalBinaryCObserver struct {
+	nominalCObserver
+	Subset bool
+}
+
+func (o *nominalBinaryCObserver) ByteSize() int {
+	return o.nominalCObserver.ByteSize() + 8
+}
+
+// BestSplit implements CObserver
+func (o *nominalBinaryCObserver) BestSplit(crit classifiers.CSplitCriterion, predictor *core.Attribute, preSplit util.Vector) *SplitSuggestion {
+	ncols := o.PostSplit.NumTargets()
+	if ncols < 2 {
+		return nil
+	}
+
+	dist := o.calcPostSplit(ncols)
+	if len(dist) < 2 {
+		return nil
+	}
+
+	total := util.NewVector()
+	values := make([]int, 0, len(dist))
+	for pi, vv := range dist {
+		vv.ForEach(func(ti int, v float64) { total = total.Incr(ti, v) })
+		values = append(values, pi)
+	}
+	sort.Ints(values)
+
+	var best *SplitSuggestion
+	try := func(subset []int) {
+		left := util.NewVector()
+		for _, pi := range subset {
+			dist[pi].ForEach(func(ti int, v float64) { left = left.Incr(ti, v) })
+		}
+
+		postSplit := binaryPostSplit(left, total)
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			return
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNominalBinarySplitCondition(predictor, append([]int(nil), subset...)...),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newCObservationStats(preSplit),
+			postStats: newCObservationStatsDist(postSplit),
+		}
+	}
+
+	if o.Subset && total.Count() == 2 {
+		first := -1
+		total.ForEach(func(ti int, v float64) {
+			if first < 0 && v > 0 {
+				first = ti
+			}
+		})
+		sort.SliceStable(values, func(i, j int) bool {
+			vi, vj := dist[values[i]], dist[values[j]]
+			return vi.Get(first)/vi.Sum() < vj.Get(first)/vj.Sum()
+		})
+		for n := 1; n < len(values); n++ {
+			try(values[:n])
+		}
+		return best
+	}
+
+	for i := range values {
+		try(values[i : i+1])
+	}
+	return best
+}
+
+func (o *nominalBinaryCObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.PostSplit, o.Subset)
+}
+
+func (o *nominalBinaryCObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.PostSplit, &o.Subset)
+}
+
+// --------------------------------------------------------------------
+
+// NewNominalBinaryRObserver monitors a nominal predictor attribute for a
+// numeric regression target and suggests binary splits which separate a
+// single value from the rest. If subset is true, values are ordered by their
+// mean target and the best subset of values is separated from the rest
+// instead. See NewNominalBinaryCObserver.
+func NewNominalBinaryRObserver(subset bool) RObserver {
+	return &nominalBinaryRObserver{
+		nominalRObserver: nominalRObserver{PostSplit: util.NewNumSeriesDistribution()},
+		Subset:           subset,
+	}
+}
+
+type nominalBinaryRObserver struct {
+	nominalRObserver
+	Subset bool
+}
+
+func (o *nominalBinaryRObserver) ByteSize() int {
+	return o.nominalRObserver.ByteSize() + 8
+}
+
+// BestSplit implements RObserver
+func (o *nominalBinaryRObserver) BestSplit(crit classifiers.RSplitCriterion, predictor *core.Attribute, preSplit *util.NumSeries) *SplitSuggestion {
+	if !o.isSplitable() {
+		return nil
+	}
+
+	values := make([]int, 0, len(o.PostSplit))
+	for pi := range o.PostSplit {
+		values = append(values, pi)
+	}
+	sort.Ints(values)
+	if o.Subset {
+		sort.SliceStable(values, func(i, j int) bool {
+			return o.PostSplit[values[i]].Mean() < o.PostSplit[values[j]].Mean()
+		})
+	}
+
+	// Accumulate the series of all values before and after each position
+	prefix := make([]util.NumSeries, len(values)+1)
+	suffix := make([]util.NumSeries, len(values)+1)
+	for i, pi := range values {
+		prefix[i+1] = prefix[i]
+		prefix[i+1].Merge(o.PostSplit[pi])
+	}
+	for i := len(values) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1]
+		suffix[i].Merge(o.PostSplit[values[i]])
+	}
+
+	var best *SplitSuggestion
+	try := func(subset []int, left, right util.NumSeries) {
+		postSplit := util.NumSeriesDistribution{0: &left, 1: &right}
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			return
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNominalBinarySplitCondition(predictor, append([]int(nil), subset...)...),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newRObservationStats(preSplit),
+			postStats: newRObservationStatsDist(postSplit),
+		}
+	}
+
+	if o.Subset {
+		for n := 1; n < len(values); n++ {
+			try(values[:n], prefix[n], suffix[n])
+		}
+		return best
+	}
+
+	for i, pi := range values {
+		rest := prefix[i]
+		rest.Merge(&suffix[i+1])
+		try(values[i:i+1], *o.PostSplit[pi], rest)
+	}
+	return best
+}
+
+func (o *nominalBinaryRObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.PostSplit, o.Subset)
+}
+
+func (o *nominalBinaryRObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.PostSplit, &o.Subset)
+}
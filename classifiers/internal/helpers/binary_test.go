@@ -0,0 +1,164 @@
+package helpers
+
+import (
+	"bytes"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/testdata"
+	"github.com/bsm/reason/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("nominalBinaryCObserver", func() {
+	var subject CObserver
+
+	model := testdata.ClassificationModel()
+	predictor := model.Predictor("outlook")
+	target := model.Target()
+	instances := testdata.ClassificationData()
+
+	BeforeEach(func() {
+		subject = NewNominalBinaryCObserver(false)
+		for _, inst := range instances {
+			subject.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		}
+	})
+
+	It("should observe", func() {
+		o := subject.(*nominalBinaryCObserver)
+		Expect(o.PostSplit).To(HaveLen(2))
+		Expect(o.ByteSize()).To(BeNumerically("~", 210, 20))
+	})
+
+	It("should calculate best split", func() {
+		s := subject.BestSplit(
+			classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1},
+			predictor,
+			util.SparseVector{0: 9.0, 1: 5.0},
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 0.226, 0.001))
+		Expect(s.Range()).To(Equal(1.0))
+		Expect(s.Condition()).To(Equal(NewNominalBinarySplitCondition(predictor, 1)))
+
+		postStats := s.PostStats()
+		Expect(postStats).To(HaveLen(2))
+		Expect(postStats[0].State()).To(ConsistOf(core.Prediction{
+			{AttributeValue: 0, Votes: 4},
+		}))
+		Expect(postStats[1].State()).To(ConsistOf(core.Prediction{
+			{AttributeValue: 0, Votes: 5},
+			{AttributeValue: 1, Votes: 5},
+		}))
+	})
+
+	It("should calculate best subset split", func() {
+		o := NewNominalBinaryCObserver(true)
+		for _, inst := range instances {
+			o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		}
+
+		s := o.BestSplit(
+			classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1},
+			predictor,
+			util.SparseVector{0: 9.0, 1: 5.0},
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 0.226, 0.001))
+		Expect(s.Condition()).To(Equal(NewNominalBinarySplitCondition(predictor, 0, 2)))
+	})
+
+	It("should require at least two observed values for best split", func() {
+		inst := instances[0]
+
+		o := NewNominalBinaryCObserver(false)
+		o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+
+		Expect(o.BestSplit(
+			classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1},
+			predictor,
+			util.SparseVector{0: 2.0},
+		)).To(BeNil())
+	})
+
+	It("should encode/decode", func() {
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out CObserver
+		err = msgpack.NewDecoder(buf).Decode(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+	})
+})
+
+var _ = Describe("nominalBinaryRObserver", func() {
+	var subject RObserver
+	var preSplit *util.NumSeries
+
+	model := testdata.RegressionModel()
+	predictor := model.Predictor("outlook")
+	target := model.Target()
+	instances := testdata.RegressionData()
+
+	BeforeEach(func() {
+		subject = NewNominalBinaryRObserver(false)
+		preSplit = new(util.NumSeries)
+
+		for _, inst := range instances {
+			tv := target.Value(inst)
+			subject.Observe(tv, predictor.Value(inst), inst.GetInstanceWeight())
+			preSplit.Append(tv.Value(), inst.GetInstanceWeight())
+		}
+	})
+
+	It("should observe", func() {
+		o := subject.(*nominalBinaryRObserver)
+		Expect(o.PostSplit).To(HaveLen(3))
+		Expect(o.ByteSize()).To(BeNumerically("~", 1060, 20))
+	})
+
+	It("should calculate best split", func() {
+		s := subject.BestSplit(
+			classifiers.VarReductionSplitCriterion{},
+			predictor,
+			preSplit,
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 16.715, 0.001))
+		Expect(s.Range()).To(Equal(1.0))
+		Expect(s.Condition()).To(Equal(NewNominalBinarySplitCondition(predictor, 1)))
+	})
+
+	It("should calculate best subset split", func() {
+		o := NewNominalBinaryRObserver(true)
+		for _, inst := range instances {
+			o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		}
+
+		s := o.BestSplit(
+			classifiers.VarReductionSplitCriterion{},
+			predictor,
+			preSplit,
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 16.715, 0.001))
+		Expect(s.Condition()).To(Equal(NewNominalBinarySplitCondition(predictor, 0, 2)))
+	})
+
+	It("should encode/decode", func() {
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out RObserver
+		err = msgpack.NewDecoder(buf).Decode(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+	})
+})
@@ -0,0 +1,262 @@
+package helpers
+
+import (
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+)
+
+func init() {
+	msgpack.Register(7758, (*ebstCObserver)(nil))
+	msgpack.Register(7759, (*ebstRObserver)(nil))
+	msgpack.Register(7760, (*ebstCNode)(nil))
+	msgpack.Register(7761, (*ebstRNode)(nil))
+}
+
+// NewEBSTCObserver uses an exhaustive binary search tree to monitor a
+// numeric predictor attribute. It evaluates every distinct observed value
+// as a split point. Memory grows with the number of distinct values.
+func NewEBSTCObserver() CObserver {
+	return &ebstCObserver{
+		PostSplit: util.NewNumSeriesDistribution(),
+	}
+}
+
+type ebstCObserver struct {
+	intervals
+	Root      *ebstCNode
+	PostSplit util.NumSeriesDistribution
+	Size      int
+}
+
+type ebstCNode struct {
+	Value       float64
+	Classes     util.Vector
+	Left, Right *ebstCNode
+}
+
+func (o *ebstCObserver) ByteSize() int {
+	size := 40 + o.PostSplit.ByteSize() + o.Size*48
+	o.Root.walk(func(n *ebstCNode) { size += n.Classes.ByteSize() })
+	return size
+}
+
+// Observe implements CObserver
+func (o *ebstCObserver) Observe(tv, pv core.AttributeValue, weight float64) {
+	ti, pval := tv.Index(), pv.Value()
+	o.PostSplit.Append(ti, pval, weight)
+
+	node := &o.Root
+	for *node != nil && (*node).Value != pval {
+		if pval < (*node).Value {
+			node = &(*node).Left
+		} else {
+			node = &(*node).Right
+		}
+	}
+	if *node == nil {
+		*node = &ebstCNode{Value: pval, Classes: util.NewVector()}
+		o.Size++
+	}
+	(*node).Classes = (*node).Classes.Incr(ti, weight)
+}
+
+// Probability implements CObserver
+func (o *ebstCObserver) Probability(tv, pv core.AttributeValue) float64 {
+	if est := o.PostSplit.Get(tv.Index()); est != nil {
+		return est.ProbDensity(pv.Value())
+	}
+	return 0.0
+}
+
+// BestSplit implements CObserver
+func (o *ebstCObserver) BestSplit(crit classifiers.CSplitCriterion, predictor *core.Attribute, preSplit util.Vector) *SplitSuggestion {
+	total := util.NewVector()
+	o.Root.walk(func(n *ebstCNode) {
+		n.Classes.ForEach(func(i int, v float64) { total = total.Incr(i, v) })
+	})
+
+	var best *SplitSuggestion
+	cuts := make([]float64, 0, o.Size)
+	lhs := make([]util.Vector, 0, o.Size)
+	left := util.NewVector()
+	o.Root.walk(func(n *ebstCNode) {
+		n.Classes.ForEach(func(i int, v float64) { left = left.Incr(i, v) })
+		if len(cuts) == o.Size-1 {
+			return
+		}
+		cuts = append(cuts, n.Value)
+		lhs = append(lhs, cloneVector(left))
+
+		postSplit := binaryPostSplit(left, total)
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			return
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNumericBinarySplitCondition(predictor, n.Value),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newCObservationStats(preSplit),
+			postStats: newCObservationStatsDist(postSplit),
+		}
+	})
+	return o.multiwayC(crit, predictor, preSplit, cuts, lhs, total, best)
+}
+
+func (o *ebstCObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.Root, o.PostSplit, o.Size, o.NumIntervals)
+}
+
+func (o *ebstCObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.Root, &o.PostSplit, &o.Size, &o.NumIntervals)
+}
+
+// walk traverses the tree in order
+func (n *ebstCNode) walk(fn func(*ebstCNode)) {
+	if n == nil {
+		return
+	}
+	n.Left.walk(fn)
+	fn(n)
+	n.Right.walk(fn)
+}
+
+func (n *ebstCNode) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(n.Value, n.Classes, n.Left, n.Right)
+}
+
+func (n *ebstCNode) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&n.Value, &n.Classes, &n.Left, &n.Right)
+}
+
+// binaryPostSplit returns the post-split distribution for
+// a left-hand side and a total class distribution
+func binaryPostSplit(left, total util.Vector) util.VectorDistribution {
+	res := util.NewVectorDistribution()
+	total.ForEach(func(i int, v float64) {
+		lv := left.Get(i)
+		res.Incr(0, i, lv)
+		res.Incr(1, i, v-lv)
+	})
+	return res
+}
+
+// --------------------------------------------------------------------
+
+// NewEBSTRObserver uses an exhaustive binary search tree to monitor a
+// numeric predictor attribute for a numeric regression target. See
+// NewEBSTCObserver.
+func NewEBSTRObserver() RObserver {
+	return new(ebstRObserver)
+}
+
+type ebstRObserver struct {
+	intervals
+	Root *ebstRNode
+	Size int
+}
+
+type ebstRNode struct {
+	Value       float64
+	Target      util.NumSeries
+	Left, Right *ebstRNode
+}
+
+func (o *ebstRObserver) ByteSize() int {
+	return 16 + o.Size*72
+}
+
+// Observe implements RObserver
+func (o *ebstRObserver) Observe(tv, pv core.AttributeValue, weight float64) {
+	tval, pval := tv.Value(), pv.Value()
+
+	node := &o.Root
+	for *node != nil && (*node).Value != pval {
+		if pval < (*node).Value {
+			node = &(*node).Left
+		} else {
+			node = &(*node).Right
+		}
+	}
+	if *node == nil {
+		*node = &ebstRNode{Value: pval}
+		o.Size++
+	}
+	(*node).Target.Append(tval, weight)
+}
+
+// BestSplit implements RObserver
+func (o *ebstRObserver) BestSplit(crit classifiers.RSplitCriterion, predictor *core.Attribute, preSplit *util.NumSeries) *SplitSuggestion {
+	nodes := make([]*ebstRNode, 0, o.Size)
+	o.Root.walk(func(n *ebstRNode) { nodes = append(nodes, n) })
+	if len(nodes) < 2 {
+		return nil
+	}
+
+	// Accumulate the right-hand side series
+	rhs := make([]util.NumSeries, len(nodes))
+	for i := len(nodes) - 1; i > 0; i-- {
+		if i+1 < len(nodes) {
+			rhs[i] = rhs[i+1]
+		}
+		rhs[i].Merge(&nodes[i].Target)
+	}
+
+	var best *SplitSuggestion
+	var lhs util.NumSeries
+	cuts := make([]float64, 0, len(nodes)-1)
+	lhss := make([]util.NumSeries, 0, len(nodes)-1)
+	for i, n := range nodes[:len(nodes)-1] {
+		lhs.Merge(&n.Target)
+		cuts = append(cuts, n.Value)
+		lhss = append(lhss, lhs)
+
+		left, right := lhs, rhs[i+1]
+		postSplit := util.NumSeriesDistribution{0: &left, 1: &right}
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			continue
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNumericBinarySplitCondition(predictor, n.Value),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newRObservationStats(preSplit),
+			postStats: newRObservationStatsDist(postSplit),
+		}
+	}
+
+	total := lhs
+	total.Merge(&nodes[len(nodes)-1].Target)
+	return o.multiwayR(crit, predictor, preSplit, cuts, lhss, total, best)
+}
+
+func (o *ebstRObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.Root, o.Size, o.NumIntervals)
+}
+
+func (o *ebstRObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.Root, &o.Size, &o.NumIntervals)
+}
+
+// walk traverses the tree in order
+func (n *ebstRNode) walk(fn func(*ebstRNode)) {
+	if n == nil {
+		return
+	}
+	n.Left.walk(fn)
+	fn(n)
+	n.Right.walk(fn)
+}
+
+func (n *ebstRNode) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(n.Value, &n.Target, n.Left, n.Right)
+}
+
+func (n *ebstRNode) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&n.Value, &n.Target, &n.Left, &n.Right)
+}
@@ -0,0 +1,164 @@
+package helpers
+
+import (
+	"bytes"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ebstCObserver", func() {
+	var subject CObserver
+
+	predictor := &core.Attribute{Name: "len", Kind: core.AttributeKindNumeric}
+	target := &core.Attribute{Name: "class", Kind: core.AttributeKindNominal}
+	instances := []core.Instance{
+		core.MapInstance{"len": 1.4, "class": "a"},
+		core.MapInstance{"len": 1.3, "class": "a"},
+		core.MapInstance{"len": 1.5, "class": "a"},
+		core.MapInstance{"len": 4.1, "class": "b"},
+		core.MapInstance{"len": 3.7, "class": "b"},
+		core.MapInstance{"len": 4.9, "class": "b"},
+		core.MapInstance{"len": 4.0, "class": "b"},
+		core.MapInstance{"len": 3.3, "class": "b"},
+		core.MapInstance{"len": 6.3, "class": "c"},
+		core.MapInstance{"len": 5.8, "class": "c"},
+		core.MapInstance{"len": 5.1, "class": "c"},
+		core.MapInstance{"len": 5.3, "class": "c"},
+		core.MapInstance{"len": 5.3, "class": "c"},
+	}
+
+	BeforeEach(func() {
+		subject = NewEBSTCObserver()
+		for _, inst := range instances {
+			subject.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		}
+	})
+
+	It("should observe", func() {
+		o := subject.(*ebstCObserver)
+		Expect(o.Size).To(Equal(12))
+		Expect(o.PostSplit).To(HaveLen(3))
+		Expect(o.ByteSize()).To(BeNumerically("~", 2110, 20))
+	})
+
+	It("should calculate best split", func() {
+		s := subject.BestSplit(
+			classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1},
+			predictor,
+			util.SparseVector{0: 3.0, 1: 5.0, 2: 5.0},
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 0.961, 0.001))
+		Expect(s.Range()).To(BeNumerically("~", 1.585, 0.001))
+		Expect(s.Condition()).To(BeAssignableToTypeOf(&numericBinarySplitCondition{}))
+		Expect(s.Condition().Predictor()).To(Equal("len"))
+		Expect(s.Condition().(*numericBinarySplitCondition).SplitValue).To(Equal(4.9))
+
+		postStats := s.PostStats()
+		Expect(postStats).To(HaveLen(2))
+		Expect(postStats[0].State()).To(ConsistOf(core.Prediction{
+			{AttributeValue: 0, Votes: 3},
+			{AttributeValue: 1, Votes: 5},
+		}))
+		Expect(postStats[1].State()).To(ConsistOf(core.Prediction{
+			{AttributeValue: 2, Votes: 5},
+		}))
+	})
+
+	It("should require at least two observed values for best split", func() {
+		inst := instances[0]
+
+		o := NewEBSTCObserver()
+		o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+		o.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
+
+		Expect(o.BestSplit(
+			classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1},
+			predictor,
+			util.SparseVector{0: 2.0},
+		)).To(BeNil())
+	})
+
+	It("should encode/decode", func() {
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out CObserver
+		err = msgpack.NewDecoder(buf).Decode(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+	})
+})
+
+var _ = Describe("ebstRObserver", func() {
+	var subject RObserver
+	var preSplit *util.NumSeries
+
+	predictor := &core.Attribute{Name: "area", Kind: core.AttributeKindNumeric}
+	target := &core.Attribute{Name: "price", Kind: core.AttributeKindNumeric}
+	instances := []core.MapInstance{
+		{"area": 1.1, "price": 4.5},
+		{"area": 1.2, "price": 4.5},
+		{"area": 1.5, "price": 5.0},
+		{"area": 0.9, "price": 3.8},
+		{"area": 1.3, "price": 5.8},
+		{"area": 1.5, "price": 5.6},
+		{"area": 0.8, "price": 3.2},
+		{"area": 2.6, "price": 8.2},
+		{"area": 1.0, "price": 3.9},
+		{"area": 1.6, "price": 5.1},
+		{"area": 1.8, "price": 8.7},
+		{"area": 1.6, "price": 6.0},
+	}
+
+	BeforeEach(func() {
+		subject = NewEBSTRObserver()
+		preSplit = new(util.NumSeries)
+
+		for _, inst := range instances {
+			tv := target.Value(inst)
+			pv := predictor.Value(inst)
+			subject.Observe(tv, pv, inst.GetInstanceWeight())
+			preSplit.Append(tv.Value(), inst.GetInstanceWeight())
+		}
+	})
+
+	It("should observe", func() {
+		o := subject.(*ebstRObserver)
+		Expect(o.Size).To(Equal(10))
+		Expect(o.ByteSize()).To(Equal(736))
+	})
+
+	It("should calculate best split", func() {
+		s := subject.BestSplit(
+			classifiers.VarReductionSplitCriterion{},
+			predictor,
+			preSplit,
+		)
+		Expect(s.Merit()).To(BeNumerically("~", 1.911, 0.001))
+		Expect(s.Range()).To(Equal(1.0))
+		Expect(s.Condition()).To(BeAssignableToTypeOf(&numericBinarySplitCondition{}))
+		Expect(s.Condition().Predictor()).To(Equal("area"))
+		Expect(s.Condition().(*numericBinarySplitCondition).SplitValue).To(Equal(1.6))
+	})
+
+	It("should encode/decode", func() {
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out RObserver
+		err = msgpack.NewDecoder(buf).Decode(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+	})
+})
@@ -13,6 +13,9 @@ func init() {
 	msgpack.Register(7746, Observation{})
 }
 
+// Observation is a single observation of a numeric regression observer.
+// Observers summarise observations in bins, the type is only retained to
+// decode observers dumped by earlier versions.
 type Observation struct{ PVal, TVal, Weight float64 }
 
 func (o Observation) EncodeTo(enc *msgpack.Encoder) error {
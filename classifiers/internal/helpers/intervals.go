@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"math"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/util"
+)
+
+// intervals can be embedded by numeric observers to support k-interval
+// splits. Cut points are selected greedily from the observer's candidate
+// split values, one at a time, maximising the merit of the resulting split.
+// To compete fairly with binary splits, the merit of a k-interval split is
+// normalised by log2(k), the split information of k equally weighted
+// branches. Binary merits are therefore unaffected and a k-interval split
+// is only suggested if it outperforms the best binary split.
+type intervals struct {
+	NumIntervals int
+}
+
+func (x *intervals) setNumIntervals(n int) { x.NumIntervals = n }
+
+// multiwayC returns a k-interval split suggestion if it outperforms the
+// binary suggestion. Cuts must be sorted, lhs must contain the cumulative
+// class distribution of all values less than or equal to each cut.
+func (x *intervals) multiwayC(crit classifiers.CSplitCriterion, predictor *core.Attribute, preSplit util.Vector, cuts []float64, lhs []util.Vector, total util.Vector, binary *SplitSuggestion) *SplitSuggestion {
+	if x.NumIntervals < 3 || len(cuts) < 2 {
+		return binary
+	}
+
+	postSplit := func(idx []int) util.VectorDistribution {
+		res := make(util.VectorDistribution, len(idx)+1)
+		prev := util.NewVector()
+		for j, i := range idx {
+			res[j] = subVectors(lhs[i], prev)
+			prev = lhs[i]
+		}
+		res[len(idx)] = subVectors(total, prev)
+		return res
+	}
+
+	idx, merit := greedyCuts(len(cuts), x.NumIntervals, func(idx []int) float64 {
+		return crit.Merit(preSplit, postSplit(idx))
+	})
+	if idx == nil || merit <= binary.Merit() {
+		return binary
+	}
+
+	return &SplitSuggestion{
+		cond:      NewNumericMultiwaySplitCondition(predictor, selectCuts(cuts, idx)...),
+		merit:     normMerit(merit),
+		mrange:    crit.Range(preSplit),
+		preStats:  newCObservationStats(preSplit),
+		postStats: newCObservationStatsDist(postSplit(idx)),
+	}
+}
+
+// multiwayR returns a k-interval split suggestion if it outperforms the
+// binary suggestion. Cuts must be sorted, lhs must contain the cumulative
+// target series of all values less than or equal to each cut.
+func (x *intervals) multiwayR(crit classifiers.RSplitCriterion, predictor *core.Attribute, preSplit *util.NumSeries, cuts []float64, lhs []util.NumSeries, total util.NumSeries, binary *SplitSuggestion) *SplitSuggestion {
+	if x.NumIntervals < 3 || len(cuts) < 2 {
+		return binary
+	}
+
+	postSplit := func(idx []int) util.NumSeriesDistribution {
+		res := make(util.NumSeriesDistribution, len(idx)+1)
+		prev := new(util.NumSeries)
+		for j, i := range idx {
+			series := lhs[i]
+			series.Sub(prev)
+			res[j], prev = &series, &lhs[i]
+		}
+		series := total
+		series.Sub(prev)
+		res[len(idx)] = &series
+		return res
+	}
+
+	idx, merit := greedyCuts(len(cuts), x.NumIntervals, func(idx []int) float64 {
+		return crit.Merit(preSplit, postSplit(idx))
+	})
+	if idx == nil || merit <= binary.Merit() {
+		return binary
+	}
+
+	return &SplitSuggestion{
+		cond:      NewNumericMultiwaySplitCondition(predictor, selectCuts(cuts, idx)...),
+		merit:     normMerit(merit),
+		mrange:    crit.Range(preSplit),
+		preStats:  newRObservationStats(preSplit),
+		postStats: newRObservationStatsDist(postSplit(idx)),
+	}
+}
+
+// greedyCuts selects up to k-1 out of n cut indices, adding the cut which
+// maximises the merit in each step and stopping if the merit doesn't
+// improve. It returns the sorted cut indices of the k-interval split with
+// the highest normalised merit, or nil if no such split was found.
+func greedyCuts(n, k int, merit func([]int) float64) ([]int, float64) {
+	var cuts, bestCuts []int
+	last, best := math.Inf(-1), math.Inf(-1)
+
+	for len(cuts) < k-1 {
+		var next []int
+		nextMerit := last
+
+		for c := 0; c < n; c++ {
+			trial, ok := insertCut(cuts, c)
+			if !ok {
+				continue
+			}
+			if m := merit(trial); m > nextMerit {
+				next, nextMerit = trial, m
+			}
+		}
+		if next == nil {
+			break
+		}
+		cuts, last = next, nextMerit
+
+		// Normalise by the split information of len(cuts)+1 branches
+		if len(cuts) < 2 {
+			continue
+		}
+		if norm := last / math.Log2(float64(len(cuts)+1)); norm > best {
+			bestCuts, best = cuts, norm
+		}
+	}
+	return bestCuts, best
+}
+
+// insertCut returns a copy of the sorted cuts with c inserted,
+// returns false if c is already included
+func insertCut(cuts []int, c int) ([]int, bool) {
+	res := make([]int, 0, len(cuts)+1)
+	for i, x := range cuts {
+		if x == c {
+			return nil, false
+		} else if x > c {
+			res = append(res, c)
+			return append(res, cuts[i:]...), true
+		}
+		res = append(res, x)
+	}
+	return append(res, c), true
+}
+
+func selectCuts(cuts []float64, idx []int) []float64 {
+	res := make([]float64, len(idx))
+	for j, i := range idx {
+		res[j] = cuts[i]
+	}
+	return res
+}
+
+// cloneVector returns a copy of a vector
+func cloneVector(v util.Vector) util.Vector {
+	res := util.NewVector()
+	v.ForEach(func(i int, x float64) { res = res.Incr(i, x) })
+	return res
+}
+
+// subVectors returns a new vector with the difference a-b
+func subVectors(a, b util.Vector) util.Vector {
+	res := cloneVector(a)
+	b.ForEach(func(i int, x float64) { res = res.Incr(i, -x) })
+	return res
+}
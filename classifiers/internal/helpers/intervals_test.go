@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"bytes"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("intervals", func() {
+	model := core.NewModel(
+		&core.Attribute{Name: "class", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("a", "b")},
+		&core.Attribute{Name: "hour", Kind: core.AttributeKindNumeric},
+	)
+	predictor := model.Predictor("hour")
+	target := model.Target()
+
+	observeC := func(o CObserver) util.Vector {
+		preSplit := util.NewVector()
+		for h := 0; h < 24; h++ {
+			class := "a"
+			if h > 8 && h < 18 {
+				class = "b"
+			}
+			inst := core.MapInstance{"hour": float64(h), "class": class}
+			o.Observe(target.Value(inst), predictor.Value(inst), 1)
+			preSplit = preSplit.Incr(target.Value(inst).Index(), 1)
+		}
+		return preSplit
+	}
+
+	DescribeTable("should suggest k-interval splits",
+		func(factory func() CObserver) {
+			o := factory()
+			preSplit := observeC(o)
+			crit := classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1}
+
+			binary := o.BestSplit(crit, predictor, preSplit)
+			Expect(binary.Condition()).To(BeAssignableToTypeOf(&numericBinarySplitCondition{}))
+
+			o.(intervalObserver).setNumIntervals(3)
+			s := o.BestSplit(crit, predictor, preSplit)
+			Expect(s.Merit()).To(BeNumerically(">", binary.Merit()))
+			Expect(s.Condition()).To(BeAssignableToTypeOf(&numericMultiwaySplitCondition{}))
+			Expect(s.PostStats()).To(HaveLen(3))
+
+			cond := s.Condition()
+			Expect(cond.Branch(core.MapInstance{"hour": 3.0})).To(Equal(0))
+			Expect(cond.Branch(core.MapInstance{"hour": 12.0})).To(Equal(1))
+			Expect(cond.Branch(core.MapInstance{"hour": 21.0})).To(Equal(2))
+
+			buf := new(bytes.Buffer)
+			enc := msgpack.NewEncoder(buf)
+			Expect(enc.Encode(o)).To(Succeed())
+			Expect(enc.Close()).To(Succeed())
+
+			var o2 CObserver
+			Expect(msgpack.NewDecoder(buf).Decode(&o2)).To(Succeed())
+			Expect(o2).To(Equal(o))
+		},
+
+		Entry("gaussian", func() CObserver { return NewNumericCObserver(24) }),
+		Entry("E-BST", func() CObserver { return NewEBSTCObserver() }),
+		Entry("sketch", func() CObserver { return NewSketchCObserver(24) }),
+	)
+
+	It("should prefer binary splits if sufficient", func() {
+		o := NewEBSTCObserver()
+		o.(intervalObserver).setNumIntervals(4)
+
+		preSplit := util.NewVector()
+		for h := 0; h < 24; h++ {
+			class := "a"
+			if h > 11 {
+				class = "b"
+			}
+			inst := core.MapInstance{"hour": float64(h), "class": class}
+			o.Observe(target.Value(inst), predictor.Value(inst), 1)
+			preSplit = preSplit.Incr(target.Value(inst).Index(), 1)
+		}
+
+		s := o.BestSplit(classifiers.InfoGainSplitCriterion{MinBranchFrac: 0.1}, predictor, preSplit)
+		Expect(s.Merit()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(s.Condition()).To(BeAssignableToTypeOf(&numericBinarySplitCondition{}))
+	})
+})
@@ -14,6 +14,115 @@ func init() {
 	msgpack.Register(7740, (*gaussianRObserver)(nil))
 }
 
+// NumericObserver is a numeric observer implementation
+type NumericObserver uint8
+
+const (
+	// NumericObserverGaussian evaluates equal-width split points
+	NumericObserverGaussian NumericObserver = iota
+	// NumericObserverEBST evaluates all observed values as split points
+	NumericObserverEBST
+	// NumericObserverSketch evaluates the boundaries of a bounded
+	// streaming histogram as split points
+	NumericObserverSketch
+)
+
+// NominalSplit is a nominal split type
+type NominalSplit uint8
+
+const (
+	// NominalSplitMultiway creates one branch per value
+	NominalSplitMultiway NominalSplit = iota
+	// NominalSplitBinary separates a single value from the rest
+	NominalSplitBinary
+	// NominalSplitSubset separates a subset of values from the rest
+	NominalSplitSubset
+)
+
+// ObserverConfig configures observers
+type ObserverConfig struct {
+	// Numeric is the numeric observer implementation
+	Numeric NumericObserver
+	// NumBins is the number of split points of gaussian observers
+	// or the number of bins of sketches
+	NumBins int
+	// Nominal is the split type suggested by nominal observers
+	Nominal NominalSplit
+	// NumIntervals is the maximum number of intervals numeric
+	// observers may suggest, values <3 disable k-interval splits
+	NumIntervals int
+}
+
+// intervalObserver is implemented by numeric observers
+// which support k-interval splits
+type intervalObserver interface {
+	setNumIntervals(int)
+}
+
+func (c *ObserverConfig) newNominalCObserver() CObserver {
+	if c == nil {
+		return NewNominalCObserver()
+	}
+
+	switch c.Nominal {
+	case NominalSplitBinary:
+		return NewNominalBinaryCObserver(false)
+	case NominalSplitSubset:
+		return NewNominalBinaryCObserver(true)
+	}
+	return NewNominalCObserver()
+}
+
+func (c *ObserverConfig) newNominalRObserver() RObserver {
+	if c == nil {
+		return NewNominalRObserver()
+	}
+
+	switch c.Nominal {
+	case NominalSplitBinary:
+		return NewNominalBinaryRObserver(false)
+	case NominalSplitSubset:
+		return NewNominalBinaryRObserver(true)
+	}
+	return NewNominalRObserver()
+}
+
+func (c *ObserverConfig) newNumericCObserver() CObserver {
+	if c == nil {
+		return NewNumericCObserver(10)
+	}
+
+	var o CObserver
+	switch c.Numeric {
+	case NumericObserverEBST:
+		o = NewEBSTCObserver()
+	case NumericObserverSketch:
+		o = NewSketchCObserver(c.NumBins)
+	default:
+		o = NewNumericCObserver(c.NumBins)
+	}
+	o.(intervalObserver).setNumIntervals(c.NumIntervals)
+	return o
+}
+
+func (c *ObserverConfig) newNumericRObserver() RObserver {
+	if c == nil {
+		return NewNumericRObserver(10)
+	}
+
+	var o RObserver
+	switch c.Numeric {
+	case NumericObserverEBST:
+		o = NewEBSTRObserver()
+	case NumericObserverSketch:
+		o = NewSketchRObserver(c.NumBins)
+	default:
+		o = NewNumericRObserver(c.NumBins)
+	}
+	o.(intervalObserver).setNumIntervals(c.NumIntervals)
+	return o
+}
+
 // Observer instances monitor and collect distribution stats
 type Observer interface {
 	// Observe records an instance and updates the attribute stats
@@ -105,6 +214,7 @@ func NewNumericCObserver(numBins int) CObserver {
 }
 
 type gaussianCObserver struct {
+	intervals
 	NumBins   int
 	Range     *MinMaxRanges
 	PostSplit util.NumSeriesDistribution
@@ -135,8 +245,12 @@ func (o *gaussianCObserver) Probability(tv, pv core.AttributeValue) float64 {
 func (o *gaussianCObserver) BestSplit(crit classifiers.CSplitCriterion, predictor *core.Attribute, preSplit util.Vector) *SplitSuggestion {
 	var best *SplitSuggestion
 
-	for _, splitVal := range o.Range.SplitPoints(o.NumBins) {
+	splitVals := o.Range.SplitPoints(o.NumBins)
+	lhs := make([]util.Vector, 0, len(splitVals))
+	for _, splitVal := range splitVals {
 		postSplit := o.binarySplitOn(splitVal)
+		lhs = append(lhs, postSplit[0])
+
 		merit := crit.Merit(preSplit, postSplit)
 		if best != nil && merit <= best.merit {
 			continue
@@ -150,7 +264,12 @@ func (o *gaussianCObserver) BestSplit(crit classifiers.CSplitCriterion, predicto
 			postStats: newCObservationStatsDist(postSplit),
 		}
 	}
-	return best
+
+	total := util.NewVector()
+	for i, est := range o.PostSplit {
+		total = total.Incr(i, est.TotalWeight())
+	}
+	return o.multiwayC(crit, predictor, preSplit, splitVals, lhs, total, best)
 }
 
 func (o *gaussianCObserver) binarySplitOn(splitVal float64) util.VectorDistribution {
@@ -170,11 +289,23 @@ func (o *gaussianCObserver) binarySplitOn(splitVal float64) util.VectorDistribut
 }
 
 func (o *gaussianCObserver) EncodeTo(enc *msgpack.Encoder) error {
-	return enc.Encode(o.NumBins, o.Range, o.PostSplit)
+	if err := enc.EncodeVersion(1); err != nil {
+		return err
+	}
+	return enc.Encode(o.NumBins, o.Range, o.PostSplit, o.NumIntervals)
 }
 
 func (o *gaussianCObserver) DecodeFrom(dec *msgpack.Decoder) error {
-	return dec.Decode(&o.NumBins, &o.Range, &o.PostSplit)
+	version, err := dec.DecodeVersion()
+	if err != nil {
+		return err
+	}
+
+	// unversioned observers only suggested binary splits
+	if err := dec.Decode(&o.NumBins, &o.Range, &o.PostSplit); err != nil || version == 0 {
+		return err
+	}
+	return dec.Decode(&o.NumIntervals)
 }
 
 // --------------------------------------------------------------------
@@ -229,8 +360,11 @@ func (o *nominalRObserver) isSplitable() bool { return len(o.PostSplit) > 1 }
 func (o *nominalRObserver) EncodeTo(enc *msgpack.Encoder) error   { return enc.Encode(o.PostSplit) }
 func (o *nominalRObserver) DecodeFrom(dec *msgpack.Decoder) error { return dec.Decode(&o.PostSplit) }
 
-// NewNumericRObserver uses gaussian estimators to monitor a numeric predictor
-// attribute for a numeric regression target
+// NewNumericRObserver monitors a numeric predictor attribute for a numeric
+// regression target. It evaluates numBins equal-width split points between
+// the observed minimum and maximum. Target stats are summarised in a bounded
+// histogram of up to numBins*5 bins, the closest bins are merged once the
+// limit is exceeded.
 func NewNumericRObserver(numBins int) RObserver {
 	if numBins < 1 {
 		numBins = 10
@@ -243,30 +377,36 @@ func NewNumericRObserver(numBins int) RObserver {
 }
 
 type gaussianRObserver struct {
-	NumBins      int
-	Range        *MinMaxRange
-	Observations []Observation
+	intervals
+	NumBins int
+	Range   *MinMaxRange
+	Bins    []*sketchRBin
 }
 
 func (o *gaussianRObserver) ByteSize() int {
-	return 80 + len(o.Observations)*24
+	return 80 + len(o.Bins)*48
 }
 
 // Observe implements RObserver
 func (o *gaussianRObserver) Observe(tv, pv core.AttributeValue, weight float64) {
 	tval, pval := tv.Value(), pv.Value()
 	o.Range.Update(pval)
-	o.Observations = append(o.Observations, Observation{
-		PVal:   pval,
-		TVal:   tval,
-		Weight: weight,
-	})
+	o.Bins = observeRBins(o.Bins, o.NumBins*5, pval, tval, weight)
 }
 
 func (o *gaussianRObserver) BestSplit(crit classifiers.RSplitCriterion, predictor *core.Attribute, preSplit *util.NumSeries) *SplitSuggestion {
 	var best *SplitSuggestion
-	for _, pivot := range o.Range.SplitPoints(o.NumBins) {
+
+	pivots := o.Range.SplitPoints(o.NumBins)
+	lhs := make([]util.NumSeries, 0, len(pivots))
+	for _, pivot := range pivots {
 		postSplit := o.postSplit(pivot)
+		if left := postSplit.Get(0); left != nil {
+			lhs = append(lhs, *left)
+		} else {
+			lhs = append(lhs, util.NumSeries{})
+		}
+
 		merit := crit.Merit(preSplit, postSplit)
 		if best != nil && merit <= best.merit {
 			continue
@@ -280,27 +420,57 @@ func (o *gaussianRObserver) BestSplit(crit classifiers.RSplitCriterion, predicto
 			postStats: newRObservationStatsDist(postSplit),
 		}
 	}
-	return best
+
+	var total util.NumSeries
+	for _, b := range o.Bins {
+		total.Merge(&b.Target)
+	}
+	return o.multiwayR(crit, predictor, preSplit, pivots, lhs, total, best)
 }
 
 func (o *gaussianRObserver) postSplit(pivot float64) util.NumSeriesDistribution {
 	res := util.NewNumSeriesDistribution()
-	for _, t := range o.Observations {
-		if t.PVal < pivot {
-			res.Append(0, t.TVal, t.Weight)
-		} else {
-			res.Append(1, t.TVal, t.Weight)
+	for _, b := range o.Bins {
+		pos := 1
+		if b.Value < pivot {
+			pos = 0
+		}
+
+		series, ok := res[pos]
+		if !ok {
+			series = new(util.NumSeries)
+			res[pos] = series
 		}
+		series.Merge(&b.Target)
 	}
 	return res
 }
 
 func (o *gaussianRObserver) EncodeTo(enc *msgpack.Encoder) error {
-	return enc.Encode(o.NumBins, o.Range, o.Observations)
+	if err := enc.EncodeVersion(1); err != nil {
+		return err
+	}
+	return enc.Encode(o.NumBins, o.Range, o.Bins, o.NumIntervals)
 }
 
 func (o *gaussianRObserver) DecodeFrom(dec *msgpack.Decoder) error {
-	return dec.Decode(&o.NumBins, &o.Range, &o.Observations)
+	version, err := dec.DecodeVersion()
+	if err != nil {
+		return err
+	}
+	if version != 0 {
+		return dec.Decode(&o.NumBins, &o.Range, &o.Bins, &o.NumIntervals)
+	}
+
+	// unversioned observers retained all observations, sketch them into bins
+	var observations []Observation
+	if err := dec.Decode(&o.NumBins, &o.Range, &observations); err != nil {
+		return err
+	}
+	for _, t := range observations {
+		o.Bins = observeRBins(o.Bins, o.NumBins*5, t.PVal, t.TVal, t.Weight)
+	}
+	return nil
 }
 
 func normMerit(merit float64) float64 {
@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	"github.com/bsm/reason/util"
+)
+
+func BenchmarkNumericRObserver_1k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewNumericRObserver(10) }, 1000)
+}
+func BenchmarkNumericRObserver_10k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewNumericRObserver(10) }, 10000)
+}
+func BenchmarkNumericRObserver_100k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewNumericRObserver(10) }, 100000)
+}
+
+func BenchmarkSketchRObserver_1k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewSketchRObserver(10) }, 1000)
+}
+func BenchmarkSketchRObserver_10k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewSketchRObserver(10) }, 10000)
+}
+func BenchmarkSketchRObserver_100k(b *testing.B) {
+	benchmarkRObserver(b, func() RObserver { return NewSketchRObserver(10) }, 100000)
+}
+
+// benchmarkRObserver observes n instances of the BigRegressionModel data,
+// then benchmarks further observations. Allocated bytes per operation
+// must remain flat, irrespective of n.
+func benchmarkRObserver(b *testing.B, factory func() RObserver, n int) {
+	model := testdata.BigRegressionModel()
+	predictor, target := model.Predictor("n1"), model.Target()
+
+	stream, err := testdata.Open("../../../testdata/bigreg.csv", model)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer stream.Close()
+
+	insts, err := stream.ReadN(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	o := factory()
+	observe := func(inst core.Instance) {
+		if pv := predictor.Value(inst); !pv.IsMissing() {
+			o.Observe(target.Value(inst), pv, 1)
+		}
+	}
+	for _, inst := range insts {
+		observe(inst)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		observe(insts[i%len(insts)])
+	}
+	b.StopTimer()
+
+	preSplit := new(util.NumSeries)
+	preSplit.Append(0, 1)
+	if o.BestSplit(classifiers.VarReductionSplitCriterion{}, predictor, preSplit) == nil {
+		b.Fatal("expected a split suggestion")
+	}
+	if size := o.ByteSize(); size > 4096 {
+		b.Fatalf("expected byte size to be bounded, but was %d", size)
+	}
+}
@@ -197,6 +197,26 @@ var _ = Describe("gaussianCObserver", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(out).To(Equal(subject))
 	})
+
+	It("should decode unversioned observers", func() {
+		o := subject.(*gaussianCObserver)
+		o.setNumIntervals(4)
+
+		buf := bytes.NewBuffer([]byte{0xd5, 8, 0x1e, 0x3a}) // type code 7738
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(o.NumBins, o.Range, o.PostSplit, "next")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out CObserver
+		var next string
+		err = msgpack.NewDecoder(buf).Decode(&out, &next)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal("next"))
+
+		o.setNumIntervals(0)
+		Expect(out).To(Equal(subject))
+	})
 })
 
 var _ = Describe("nominalRObserver", func() {
@@ -307,8 +327,8 @@ var _ = Describe("gaussianRObserver", func() {
 	It("should observe", func() {
 		o := subject.(*gaussianRObserver)
 		Expect(o.Range.SplitPoints(5)).To(Equal([]float64{1.1, 1.4, 1.7, 2, 2.3}))
-		Expect(o.Observations).To(HaveLen(12))
-		Expect(o.ByteSize()).To(Equal(368))
+		Expect(o.Bins).To(HaveLen(10))
+		Expect(o.ByteSize()).To(Equal(560))
 	})
 
 	It("should calculate best split", func() {
@@ -324,6 +344,42 @@ var _ = Describe("gaussianRObserver", func() {
 		Expect(s.Condition().(*numericBinarySplitCondition).SplitValue).To(Equal(1.7))
 	})
 
+	It("should bound memory", func() {
+		model := testdata.BigRegressionModel()
+		predictor, target := model.Predictor("n1"), model.Target()
+
+		stream, err := testdata.Open("../../../testdata/bigreg.csv", model)
+		Expect(err).NotTo(HaveOccurred())
+		defer stream.Close()
+
+		o := NewNumericRObserver(10).(*gaussianRObserver)
+		preSplit := new(util.NumSeries)
+		n, size := 0, 0
+		for stream.Next() {
+			inst := stream.Instance()
+			tv, pv := target.Value(inst), predictor.Value(inst)
+			if pv.IsMissing() {
+				continue
+			}
+
+			o.Observe(tv, pv, 1)
+			preSplit.Append(tv.Value(), 1)
+			if n++; n == 1000 {
+				size = o.ByteSize()
+			}
+		}
+		Expect(stream.Err()).NotTo(HaveOccurred())
+		Expect(n).To(BeNumerically(">", 90000))
+		Expect(o.Bins).To(HaveLen(50))
+		Expect(o.ByteSize()).To(Equal(size))
+
+		s := o.BestSplit(classifiers.VarReductionSplitCriterion{}, predictor, preSplit)
+		Expect(s.Merit()).To(BeNumerically(">", 0))
+		postStats := s.PostStats()
+		Expect(postStats).To(HaveLen(2))
+		Expect(postStats[0].TotalWeight() + postStats[1].TotalWeight()).To(Equal(float64(n)))
+	})
+
 	It("should encode/decode", func() {
 		buf := new(bytes.Buffer)
 		enc := msgpack.NewEncoder(buf)
@@ -336,4 +392,26 @@ var _ = Describe("gaussianRObserver", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(out).To(Equal(subject))
 	})
+
+	It("should decode unversioned observers", func() {
+		observations := make([]Observation, 0, len(instances))
+		for _, inst := range instances {
+			observations = append(observations, Observation{
+				PVal:   predictor.Value(inst).Value(),
+				TVal:   target.Value(inst).Value(),
+				Weight: inst.GetInstanceWeight(),
+			})
+		}
+
+		buf := bytes.NewBuffer([]byte{0xd5, 8, 0x1e, 0x3c}) // type code 7740
+		enc := msgpack.NewEncoder(buf)
+		err := enc.Encode(5, subject.(*gaussianRObserver).Range, observations)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		var out RObserver
+		err = msgpack.NewDecoder(buf).Decode(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(subject))
+	})
 })
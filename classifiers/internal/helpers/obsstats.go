@@ -21,8 +21,8 @@ type ObservationStats interface {
 	IsSufficient() bool
 	// UpdatePreSplit updates pre-split stats
 	UpdatePreSplit(target core.AttributeValue, weight float64)
-	// NewObserver creates a new attribute observer
-	NewObserver(isNominal bool) Observer
+	// NewObserver creates a new attribute observer with an optional config
+	NewObserver(isNominal bool, conf *ObserverConfig) Observer
 	// TotalWeight returns the total weight observed
 	TotalWeight() float64
 	// ByteSize returns a required heap-size estimate
@@ -59,7 +59,8 @@ func newRObservationStats(preSplit *util.NumSeries) ObservationStats {
 func newRObservationStatsDist(postSplit util.NumSeriesDistribution) map[int]ObservationStats {
 	res := make(map[int]ObservationStats, len(postSplit))
 	for i, vv := range postSplit {
-		res[i] = &obsRStats{PreSplit: vv}
+		series := *vv
+		res[i] = &obsRStats{PreSplit: &series}
 	}
 	return res
 }
@@ -86,11 +87,11 @@ func (s *obsCStats) UpdatePreSplit(tv core.AttributeValue, weight float64) {
 	s.PreSplit = s.PreSplit.Incr(tv.Index(), weight)
 }
 
-func (s *obsCStats) NewObserver(isNominal bool) Observer {
+func (s *obsCStats) NewObserver(isNominal bool, conf *ObserverConfig) Observer {
 	if isNominal {
-		return NewNominalCObserver()
+		return conf.newNominalCObserver()
 	}
-	return NewNumericCObserver(10)
+	return conf.newNumericCObserver()
 }
 
 func (s *obsCStats) BestSplit(crit classifiers.SplitCriterion, obs Observer, predictor *core.Attribute) *SplitSuggestion {
@@ -134,11 +135,11 @@ func (s *obsRStats) UpdatePreSplit(tv core.AttributeValue, weight float64) {
 	s.PreSplit.Append(tv.Value(), weight)
 }
 
-func (s *obsRStats) NewObserver(isNominal bool) Observer {
+func (s *obsRStats) NewObserver(isNominal bool, conf *ObserverConfig) Observer {
 	if isNominal {
-		return NewNominalRObserver()
+		return conf.newNominalRObserver()
 	}
-	return NewNumericRObserver(10)
+	return conf.newNumericRObserver()
 }
 
 func (s *obsRStats) State() core.Prediction {
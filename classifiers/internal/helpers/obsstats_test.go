@@ -41,8 +41,11 @@ var _ = Describe("ObservationStats", func() {
 		})
 
 		It("should create new observers", func() {
-			Expect(subject.NewObserver(true)).To(BeAssignableToTypeOf(&nominalCObserver{}))
-			Expect(subject.NewObserver(false)).To(BeAssignableToTypeOf(&gaussianCObserver{}))
+			Expect(subject.NewObserver(true, nil)).To(BeAssignableToTypeOf(&nominalCObserver{}))
+			Expect(subject.NewObserver(false, nil)).To(BeAssignableToTypeOf(&gaussianCObserver{}))
+			Expect(subject.NewObserver(false, &ObserverConfig{Numeric: NumericObserverEBST})).To(BeAssignableToTypeOf(&ebstCObserver{}))
+			Expect(subject.NewObserver(false, &ObserverConfig{Numeric: NumericObserverSketch})).To(BeAssignableToTypeOf(&sketchCObserver{}))
+			Expect(subject.NewObserver(true, &ObserverConfig{Nominal: NominalSplitBinary})).To(BeAssignableToTypeOf(&nominalBinaryCObserver{}))
 		})
 
 		It("should return state", func() {
@@ -54,7 +57,7 @@ var _ = Describe("ObservationStats", func() {
 
 		It("should calculate best-splits", func() {
 			predictor := model.Predictor("outlook")
-			obs := subject.NewObserver(true)
+			obs := subject.NewObserver(true, nil)
 			for _, inst := range instances {
 				obs.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
 			}
@@ -106,8 +109,11 @@ var _ = Describe("ObservationStats", func() {
 		})
 
 		It("should create new observers", func() {
-			Expect(subject.NewObserver(true)).To(BeAssignableToTypeOf(&nominalRObserver{}))
-			Expect(subject.NewObserver(false)).To(BeAssignableToTypeOf(&gaussianRObserver{}))
+			Expect(subject.NewObserver(true, nil)).To(BeAssignableToTypeOf(&nominalRObserver{}))
+			Expect(subject.NewObserver(false, nil)).To(BeAssignableToTypeOf(&gaussianRObserver{}))
+			Expect(subject.NewObserver(false, &ObserverConfig{Numeric: NumericObserverEBST})).To(BeAssignableToTypeOf(&ebstRObserver{}))
+			Expect(subject.NewObserver(false, &ObserverConfig{Numeric: NumericObserverSketch})).To(BeAssignableToTypeOf(&sketchRObserver{}))
+			Expect(subject.NewObserver(true, &ObserverConfig{Nominal: NominalSplitSubset})).To(BeAssignableToTypeOf(&nominalBinaryRObserver{}))
 		})
 
 		It("should return state", func() {
@@ -119,7 +125,7 @@ var _ = Describe("ObservationStats", func() {
 
 		It("should calculate best-splits", func() {
 			predictor := model.Predictor("outlook")
-			obs := subject.NewObserver(true)
+			obs := subject.NewObserver(true, nil)
 			for _, inst := range instances {
 				obs.Observe(target.Value(inst), predictor.Value(inst), inst.GetInstanceWeight())
 			}
@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"math"
+
+	"github.com/bsm/reason/core"
+)
+
+// WeightedInstance wraps an instance and overrides its weight
+type WeightedInstance struct {
+	core.Instance
+	Weight float64
+}
+
+// GetInstanceWeight implements core.Instance
+func (w WeightedInstance) GetInstanceWeight() float64 { return w.Weight }
+
+// CombinePredictions combines multiple predictions, with optional weights.
+// Class votes are normalised per prediction and summed up. Regressions are
+// combined as a mixture of the predictive distributions, the resulting
+// variance accounts for both, the individual variances and the
+// disagreement between the predictions.
+func CombinePredictions(isRegression bool, predictions []core.Prediction, weights []float64) core.Prediction {
+	if isRegression {
+		return combineR(predictions, weights)
+	}
+	return combineC(predictions, weights)
+}
+
+func combineC(predictions []core.Prediction, weights []float64) core.Prediction {
+	var votes []float64
+	for i, p := range predictions {
+		sum := 0.0
+		for _, pv := range p {
+			sum += pv.Votes
+		}
+
+		weight := predictionWeight(weights, i)
+		if sum <= 0 || weight <= 0 {
+			continue
+		}
+
+		for _, pv := range p {
+			index := pv.Index()
+			if index < 0 {
+				continue
+			}
+			for len(votes) <= index {
+				votes = append(votes, 0)
+			}
+			votes[index] += weight * pv.Votes / sum
+		}
+	}
+
+	res := core.NewPrediction(len(votes))
+	for i, v := range votes {
+		if v > 0 {
+			res = append(res, core.PredictedValue{AttributeValue: core.AttributeValue(i), Votes: v})
+		}
+	}
+	return res
+}
+
+func combineR(predictions []core.Prediction, weights []float64) core.Prediction {
+	sumW, sumV, sumX, sumX2 := 0.0, 0.0, 0.0, 0.0
+	for i, p := range predictions {
+		if len(p) == 0 {
+			continue
+		}
+
+		top := p.Top()
+		value, weight := top.Value(), predictionWeight(weights, i)
+		if math.IsNaN(value) || weight <= 0 {
+			continue
+		}
+
+		variance := top.Variance
+		if math.IsNaN(variance) {
+			variance = 0
+		}
+
+		sumW += weight
+		sumV += weight * top.Votes
+		sumX += weight * value
+		sumX2 += weight * (variance + value*value)
+	}
+
+	res := core.NewPrediction(1)
+	if sumW == 0 {
+		return res
+	}
+
+	mean := sumX / sumW
+	return append(res, core.PredictedValue{
+		AttributeValue: core.AttributeValue(mean),
+		Votes:          sumV / sumW,
+		Variance:       math.Max(sumX2/sumW-mean*mean, 0),
+	})
+}
+
+func predictionWeight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+	return weights[i]
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"github.com/bsm/reason/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CombinePredictions", func() {
+
+	It("should combine classifications", func() {
+		p := CombinePredictions(false, []core.Prediction{
+			{{AttributeValue: 0, Votes: 3}, {AttributeValue: 1, Votes: 1}},
+			{{AttributeValue: 1, Votes: 8}, {AttributeValue: 2, Votes: 2}},
+			{},
+		}, nil)
+		Expect(p).To(ConsistOf(core.Prediction{
+			{AttributeValue: 0, Votes: 0.75},
+			{AttributeValue: 1, Votes: 1.05},
+			{AttributeValue: 2, Votes: 0.2},
+		}))
+		Expect(p.Index()).To(Equal(1))
+	})
+
+	It("should combine weighted classifications", func() {
+		p := CombinePredictions(false, []core.Prediction{
+			{{AttributeValue: 0, Votes: 3}, {AttributeValue: 1, Votes: 1}},
+			{{AttributeValue: 1, Votes: 8}, {AttributeValue: 2, Votes: 2}},
+		}, []float64{0.9, 0.1})
+		Expect(p.Index()).To(Equal(0))
+		Expect(p.Top().Votes).To(BeNumerically("~", 0.675, 0.001))
+	})
+
+	It("should combine regressions", func() {
+		p := CombinePredictions(true, []core.Prediction{
+			{{AttributeValue: 2, Votes: 10, Variance: 1}},
+			{{AttributeValue: 4, Votes: 20, Variance: 1}},
+			{},
+		}, nil)
+		Expect(p).To(HaveLen(1))
+		Expect(p.Value()).To(Equal(3.0))
+		Expect(p.Top().Votes).To(Equal(15.0))
+		Expect(p.Top().Variance).To(Equal(2.0))
+	})
+
+})
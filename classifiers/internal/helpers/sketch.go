@@ -0,0 +1,313 @@
+package helpers
+
+import (
+	"sort"
+
+	"github.com/bsm/reason/classifiers"
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/msgpack"
+	"github.com/bsm/reason/util"
+)
+
+func init() {
+	msgpack.Register(7762, (*sketchCObserver)(nil))
+	msgpack.Register(7763, (*sketchRObserver)(nil))
+	msgpack.Register(7764, (*sketchCBin)(nil))
+	msgpack.Register(7765, (*sketchRBin)(nil))
+}
+
+// NewSketchCObserver uses a streaming histogram with a bounded number of
+// bins to monitor a numeric predictor attribute. Once the number of bins
+// is exceeded, the two closest bins are merged. The boundaries between
+// adjacent bins are evaluated as split points. See "A Streaming Parallel
+// Decision Tree Algorithm" by Yael Ben-Haim and Elad Tom-Tov (2010).
+func NewSketchCObserver(numBins int) CObserver {
+	if numBins < 2 {
+		numBins = 10
+	}
+
+	return &sketchCObserver{
+		NumBins:   numBins,
+		PostSplit: util.NewNumSeriesDistribution(),
+	}
+}
+
+type sketchCObserver struct {
+	intervals
+	NumBins   int
+	Bins      []*sketchCBin
+	PostSplit util.NumSeriesDistribution
+}
+
+type sketchCBin struct {
+	Value, Weight float64
+	Classes       util.Vector
+}
+
+func (o *sketchCObserver) ByteSize() int {
+	size := 56 + o.PostSplit.ByteSize()
+	for _, b := range o.Bins {
+		size += 40 + b.Classes.ByteSize()
+	}
+	return size
+}
+
+// Observe implements CObserver
+func (o *sketchCObserver) Observe(tv, pv core.AttributeValue, weight float64) {
+	ti, pval := tv.Index(), pv.Value()
+	o.PostSplit.Append(ti, pval, weight)
+
+	pos := sort.Search(len(o.Bins), func(i int) bool { return o.Bins[i].Value >= pval })
+	if pos == len(o.Bins) || o.Bins[pos].Value != pval {
+		o.Bins = append(o.Bins, nil)
+		copy(o.Bins[pos+1:], o.Bins[pos:])
+		o.Bins[pos] = &sketchCBin{Value: pval, Classes: util.NewVector()}
+	}
+
+	bin := o.Bins[pos]
+	bin.Weight += weight
+	bin.Classes = bin.Classes.Incr(ti, weight)
+
+	if len(o.Bins) > o.NumBins {
+		i := closestBins(len(o.Bins), func(i int) float64 { return o.Bins[i].Value })
+		a, b := o.Bins[i], o.Bins[i+1]
+		a.Value = mergeValues(a.Value, a.Weight, b.Value, b.Weight)
+		a.Weight += b.Weight
+		b.Classes.ForEach(func(j int, v float64) { a.Classes = a.Classes.Incr(j, v) })
+		o.Bins = append(o.Bins[:i+1], o.Bins[i+2:]...)
+	}
+}
+
+// Probability implements CObserver
+func (o *sketchCObserver) Probability(tv, pv core.AttributeValue) float64 {
+	if est := o.PostSplit.Get(tv.Index()); est != nil {
+		return est.ProbDensity(pv.Value())
+	}
+	return 0.0
+}
+
+// BestSplit implements CObserver
+func (o *sketchCObserver) BestSplit(crit classifiers.CSplitCriterion, predictor *core.Attribute, preSplit util.Vector) *SplitSuggestion {
+	total := util.NewVector()
+	for _, b := range o.Bins {
+		b.Classes.ForEach(func(i int, v float64) { total = total.Incr(i, v) })
+	}
+
+	var best *SplitSuggestion
+	var cuts []float64
+	var lhs []util.Vector
+	left := util.NewVector()
+	for i := 0; i < len(o.Bins)-1; i++ {
+		o.Bins[i].Classes.ForEach(func(j int, v float64) { left = left.Incr(j, v) })
+
+		splitVal := (o.Bins[i].Value + o.Bins[i+1].Value) / 2
+		cuts = append(cuts, splitVal)
+		lhs = append(lhs, cloneVector(left))
+
+		postSplit := binaryPostSplit(left, total)
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			continue
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNumericBinarySplitCondition(predictor, splitVal),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newCObservationStats(preSplit),
+			postStats: newCObservationStatsDist(postSplit),
+		}
+	}
+	return o.multiwayC(crit, predictor, preSplit, cuts, lhs, total, best)
+}
+
+func (o *sketchCObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.NumBins, o.Bins, o.PostSplit, o.NumIntervals)
+}
+
+func (o *sketchCObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.NumBins, &o.Bins, &o.PostSplit, &o.NumIntervals)
+}
+
+func (b *sketchCBin) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(b.Value, b.Weight, b.Classes)
+}
+
+func (b *sketchCBin) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&b.Value, &b.Weight, &b.Classes)
+}
+
+// --------------------------------------------------------------------
+
+// NewSketchRObserver uses a streaming histogram with a bounded number of
+// bins to monitor a numeric predictor attribute for a numeric regression
+// target. See NewSketchCObserver.
+func NewSketchRObserver(numBins int) RObserver {
+	if numBins < 2 {
+		numBins = 10
+	}
+	return &sketchRObserver{NumBins: numBins}
+}
+
+type sketchRObserver struct {
+	intervals
+	NumBins int
+	Bins    []*sketchRBin
+}
+
+type sketchRBin struct {
+	Value  float64
+	Target util.NumSeries
+}
+
+func (o *sketchRObserver) ByteSize() int {
+	return 40 + len(o.Bins)*48
+}
+
+// Observe implements RObserver
+func (o *sketchRObserver) Observe(tv, pv core.AttributeValue, weight float64) {
+	o.Bins = observeRBins(o.Bins, o.NumBins, pv.Value(), tv.Value(), weight)
+}
+
+// BestSplit implements RObserver
+func (o *sketchRObserver) BestSplit(crit classifiers.RSplitCriterion, predictor *core.Attribute, preSplit *util.NumSeries) *SplitSuggestion {
+	if len(o.Bins) < 2 {
+		return nil
+	}
+
+	// Accumulate the right-hand side series
+	rhs := make([]util.NumSeries, len(o.Bins))
+	for i := len(o.Bins) - 1; i > 0; i-- {
+		if i+1 < len(o.Bins) {
+			rhs[i] = rhs[i+1]
+		}
+		rhs[i].Merge(&o.Bins[i].Target)
+	}
+
+	var best *SplitSuggestion
+	var lhs util.NumSeries
+	cuts := make([]float64, 0, len(o.Bins)-1)
+	lhss := make([]util.NumSeries, 0, len(o.Bins)-1)
+	for i := 0; i < len(o.Bins)-1; i++ {
+		lhs.Merge(&o.Bins[i].Target)
+
+		splitVal := (o.Bins[i].Value + o.Bins[i+1].Value) / 2
+		cuts = append(cuts, splitVal)
+		lhss = append(lhss, lhs)
+
+		left, right := lhs, rhs[i+1]
+		postSplit := util.NumSeriesDistribution{0: &left, 1: &right}
+		merit := crit.Merit(preSplit, postSplit)
+		if best != nil && merit <= best.merit {
+			continue
+		}
+
+		best = &SplitSuggestion{
+			cond:      NewNumericBinarySplitCondition(predictor, splitVal),
+			merit:     normMerit(merit),
+			mrange:    crit.Range(preSplit),
+			preStats:  newRObservationStats(preSplit),
+			postStats: newRObservationStatsDist(postSplit),
+		}
+	}
+
+	total := lhs
+	total.Merge(&o.Bins[len(o.Bins)-1].Target)
+	return o.multiwayR(crit, predictor, preSplit, cuts, lhss, total, best)
+}
+
+func (o *sketchRObserver) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(o.NumBins, o.Bins, o.NumIntervals)
+}
+
+func (o *sketchRObserver) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&o.NumBins, &o.Bins, &o.NumIntervals)
+}
+
+func (b *sketchRBin) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(b.Value, &b.Target)
+}
+
+func (b *sketchRBin) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&b.Value, &b.Target)
+}
+
+// --------------------------------------------------------------------
+
+// observeRBins adds a target value to the bin of a predictor value and
+// returns the updated bins. Once maxBins is reached, the value is either
+// merged into its closest neighbour or the two closest bins are merged and
+// the released bin is reused for the value, whichever is closer. Memory is
+// therefore allocated only until maxBins is reached.
+func observeRBins(bins []*sketchRBin, maxBins int, pval, tval, weight float64) []*sketchRBin {
+	pos := sort.Search(len(bins), func(i int) bool { return bins[i].Value >= pval })
+	if pos < len(bins) && bins[pos].Value == pval {
+		bins[pos].Target.Append(tval, weight)
+		return bins
+	}
+
+	if len(bins) < maxBins {
+		bin := &sketchRBin{Value: pval}
+		bin.Target.Append(tval, weight)
+		return insertRBin(bins, pos, bin)
+	}
+
+	// Find the closest bins and check if a neighbour of the value is closer
+	i := closestBins(len(bins), func(i int) float64 { return bins[i].Value })
+	gap, nb := bins[i+1].Value-bins[i].Value, -1
+	if pos > 0 && pval-bins[pos-1].Value < gap {
+		gap, nb = pval-bins[pos-1].Value, pos-1
+	}
+	if pos < len(bins) && bins[pos].Value-pval < gap {
+		nb = pos
+	}
+
+	if nb > -1 {
+		bin := bins[nb]
+		bin.Value = mergeValues(bin.Value, bin.Target.TotalWeight(), pval, weight)
+		bin.Target.Append(tval, weight)
+		return bins
+	}
+
+	// Merge the closest bins and reuse the second one. As the value is not
+	// located between them, its position is either before or after both.
+	a, b := bins[i], bins[i+1]
+	a.Value = mergeValues(a.Value, a.Target.TotalWeight(), b.Value, b.Target.TotalWeight())
+	a.Target.Merge(&b.Target)
+	bins = append(bins[:i+1], bins[i+2:]...)
+	if pos > i {
+		pos--
+	}
+
+	*b = sketchRBin{Value: pval}
+	b.Target.Append(tval, weight)
+	return insertRBin(bins, pos, b)
+}
+
+// insertRBin inserts a bin at a given position
+func insertRBin(bins []*sketchRBin, pos int, bin *sketchRBin) []*sketchRBin {
+	bins = append(bins, nil)
+	copy(bins[pos+1:], bins[pos:])
+	bins[pos] = bin
+	return bins
+}
+
+// closestBins returns the index of the first of the two adjacent
+// bins with the smallest distance
+func closestBins(n int, value func(int) float64) int {
+	pos := 0
+	for i := 1; i < n-1; i++ {
+		if value(i+1)-value(i) < value(pos+1)-value(pos) {
+			pos = i
+		}
+	}
+	return pos
+}
+
+// mergeValues returns the weighted mean of two values
+func mergeValues(a, aw, b, bw float64) float64 {
+	if sum := aw + bw; sum > 0 {
+		return (a*aw + b*bw) / sum
+	}
+	return (a + b) / 2
+}
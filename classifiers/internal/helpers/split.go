@@ -3,6 +3,7 @@ package helpers
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/bsm/reason/core"
 	"github.com/bsm/reason/internal/msgpack"
@@ -75,12 +76,16 @@ func (p SplitSuggestions) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
 var (
 	_ SplitCondition = (*nominalMultiwaySplitCondition)(nil)
+	_ SplitCondition = (*nominalBinarySplitCondition)(nil)
 	_ SplitCondition = (*numericBinarySplitCondition)(nil)
+	_ SplitCondition = (*numericMultiwaySplitCondition)(nil)
 )
 
 func init() {
 	msgpack.Register(7743, (*nominalMultiwaySplitCondition)(nil))
 	msgpack.Register(7744, (*numericBinarySplitCondition)(nil))
+	msgpack.Register(7766, (*nominalBinarySplitCondition)(nil))
+	msgpack.Register(7769, (*numericMultiwaySplitCondition)(nil))
 }
 
 type SplitCondition interface {
@@ -129,6 +134,75 @@ func (c *nominalMultiwaySplitCondition) DecodeFrom(dec *msgpack.Decoder) error {
 	return nil
 }
 
+// NewNominalBinarySplitCondition inits a new split-condition which
+// separates instances with one of the given (indexed) values from the rest
+func NewNominalBinarySplitCondition(predictor *core.Attribute, values ...int) SplitCondition {
+	sort.Ints(values)
+	return &nominalBinarySplitCondition{
+		Attribute: predictor,
+		Values:    values,
+	}
+}
+
+type nominalBinarySplitCondition struct {
+	*core.Attribute
+	Values []int
+}
+
+func (c *nominalBinarySplitCondition) Predictor() string { return c.Attribute.Name }
+func (c *nominalBinarySplitCondition) Branch(inst core.Instance) int {
+	v := c.Attribute.Value(inst)
+	if v.IsMissing() {
+		return -1
+	}
+
+	index := v.Index()
+	if pos := sort.SearchInts(c.Values, index); pos < len(c.Values) && c.Values[pos] == index {
+		return 0
+	}
+	return 1
+}
+func (c *nominalBinarySplitCondition) Describe(branch int) string {
+	vals := c.Attribute.Values.Values()
+	names := make([]string, 0, len(c.Values))
+	for _, index := range c.Values {
+		if index < len(vals) {
+			names = append(names, vals[index])
+		}
+	}
+
+	if len(names) == 1 {
+		if branch == 0 {
+			return "== " + names[0]
+		} else if branch == 1 {
+			return "!= " + names[0]
+		}
+		return ""
+	}
+
+	if branch == 0 {
+		return "in {" + strings.Join(names, ", ") + "}"
+	} else if branch == 1 {
+		return "not in {" + strings.Join(names, ", ") + "}"
+	}
+	return ""
+}
+
+func (c *nominalBinarySplitCondition) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(c.Predictor(), c.Values)
+}
+
+func (c *nominalBinarySplitCondition) DecodeFrom(dec *msgpack.Decoder) error {
+	model := dec.Context().Value(core.ModelContextKey).(*core.Model)
+	var name string
+	if err := dec.Decode(&name); err != nil {
+		return err
+	}
+
+	c.Attribute = model.Predictor(name)
+	return dec.Decode(&c.Values)
+}
+
 // NewNumericBinarySplitCondition inits a new split-condition
 func NewNumericBinarySplitCondition(predictor *core.Attribute, splitValue float64) SplitCondition {
 	return &numericBinarySplitCondition{
@@ -177,3 +251,55 @@ func (c *numericBinarySplitCondition) DecodeFrom(dec *msgpack.Decoder) error {
 	c.Attribute = model.Predictor(name)
 	return dec.Decode(&c.SplitValue)
 }
+
+// NewNumericMultiwaySplitCondition inits a new split-condition which
+// splits a numeric predictor into len(splitValues)+1 intervals
+func NewNumericMultiwaySplitCondition(predictor *core.Attribute, splitValues ...float64) SplitCondition {
+	sort.Float64s(splitValues)
+	return &numericMultiwaySplitCondition{
+		Attribute:   predictor,
+		SplitValues: splitValues,
+	}
+}
+
+type numericMultiwaySplitCondition struct {
+	*core.Attribute
+	SplitValues []float64
+}
+
+func (c *numericMultiwaySplitCondition) Predictor() string { return c.Attribute.Name }
+func (c *numericMultiwaySplitCondition) Branch(inst core.Instance) int {
+	v := c.Attribute.Value(inst)
+	if v.IsMissing() {
+		return -1
+	}
+
+	n := v.Value()
+	return sort.Search(len(c.SplitValues), func(i int) bool { return n <= c.SplitValues[i] })
+}
+func (c *numericMultiwaySplitCondition) Describe(branch int) string {
+	last := len(c.SplitValues)
+	if branch == 0 && last > 0 {
+		return fmt.Sprintf("<= %f", c.SplitValues[0])
+	} else if branch == last && last > 0 {
+		return fmt.Sprintf("> %f", c.SplitValues[last-1])
+	} else if branch > 0 && branch < last {
+		return fmt.Sprintf("> %f, <= %f", c.SplitValues[branch-1], c.SplitValues[branch])
+	}
+	return ""
+}
+
+func (c *numericMultiwaySplitCondition) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(c.Predictor(), c.SplitValues)
+}
+
+func (c *numericMultiwaySplitCondition) DecodeFrom(dec *msgpack.Decoder) error {
+	model := dec.Context().Value(core.ModelContextKey).(*core.Model)
+	var name string
+	if err := dec.Decode(&name); err != nil {
+		return err
+	}
+
+	c.Attribute = model.Predictor(name)
+	return dec.Decode(&c.SplitValues)
+}
@@ -45,6 +45,49 @@ var _ = Describe("SplitCondition", func() {
 
 	})
 
+	Describe("nominalBinarySplitCondition", func() {
+		var subject SplitCondition
+		model := testdata.ClassificationModel()
+
+		BeforeEach(func() {
+			subject = NewNominalBinarySplitCondition(model.Attribute("outlook"), 2, 0)
+		})
+
+		It("should calculate branch", func() {
+			Expect(subject.Branch(core.MapInstance{"outlook": "sunny"})).To(Equal(0))
+			Expect(subject.Branch(core.MapInstance{"outlook": "overcast"})).To(Equal(1))
+			Expect(subject.Branch(core.MapInstance{"outlook": "rainy"})).To(Equal(0))
+			Expect(subject.Branch(core.MapInstance{"outlook": nil})).To(Equal(-1))
+		})
+
+		It("should describe branches", func() {
+			Expect(subject.Describe(0)).To(Equal("in {rainy, sunny}"))
+			Expect(subject.Describe(1)).To(Equal("not in {rainy, sunny}"))
+			Expect(subject.Describe(2)).To(Equal(""))
+
+			single := NewNominalBinarySplitCondition(model.Attribute("outlook"), 1)
+			Expect(single.Describe(0)).To(Equal("== overcast"))
+			Expect(single.Describe(1)).To(Equal("!= overcast"))
+		})
+
+		It("should encode/decode", func() {
+			buf := new(bytes.Buffer)
+			enc := msgpack.NewEncoder(buf)
+			err := enc.Encode(subject)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enc.Close()).NotTo(HaveOccurred())
+
+			var out SplitCondition
+			err = msgpack.NewDecoder(buf).
+				WithContext(func(ctx context.Context) context.Context {
+					return context.WithValue(ctx, core.ModelContextKey, model)
+				}).Decode(&out)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(subject))
+		})
+
+	})
+
 	Describe("numericBinarySplitCondition", func() {
 		var subject SplitCondition
 		model := core.NewModel(
@@ -80,4 +123,48 @@ var _ = Describe("SplitCondition", func() {
 		})
 	})
 
+	Describe("numericMultiwaySplitCondition", func() {
+		var subject SplitCondition
+		model := core.NewModel(
+			&core.Attribute{Name: "target"},
+			&core.Attribute{Name: "hours"},
+		)
+
+		BeforeEach(func() {
+			subject = NewNumericMultiwaySplitCondition(model.Predictor("hours"), 17, 9)
+		})
+
+		It("should calculate branch", func() {
+			Expect(subject.Branch(core.MapInstance{"hours": 8})).To(Equal(0))
+			Expect(subject.Branch(core.MapInstance{"hours": 9})).To(Equal(0))
+			Expect(subject.Branch(core.MapInstance{"hours": 12})).To(Equal(1))
+			Expect(subject.Branch(core.MapInstance{"hours": 17})).To(Equal(1))
+			Expect(subject.Branch(core.MapInstance{"hours": 18})).To(Equal(2))
+			Expect(subject.Branch(core.MapInstance{"hours": nil})).To(Equal(-1))
+		})
+
+		It("should describe branches", func() {
+			Expect(subject.Describe(0)).To(Equal("<= 9.000000"))
+			Expect(subject.Describe(1)).To(Equal("> 9.000000, <= 17.000000"))
+			Expect(subject.Describe(2)).To(Equal("> 17.000000"))
+			Expect(subject.Describe(3)).To(Equal(""))
+		})
+
+		It("should encode/decode", func() {
+			buf := new(bytes.Buffer)
+			enc := msgpack.NewEncoder(buf)
+			err := enc.Encode(subject)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enc.Close()).NotTo(HaveOccurred())
+
+			var out SplitCondition
+			err = msgpack.NewDecoder(buf).
+				WithContext(func(ctx context.Context) context.Context {
+					return context.WithValue(ctx, core.ModelContextKey, model)
+				}).Decode(&out)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(subject))
+		})
+
+	})
 })
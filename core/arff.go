@@ -0,0 +1,429 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// arffMaxLineSize is the maximum length of ARFF lines
+const arffMaxLineSize = 64 * 1024 * 1024
+
+// ARFFReader reads a model and a stream of instances from an ARFF
+// (Attribute-Relation File Format) source. Nominal attributes are defined
+// by their values, numeric, real and integer attributes become numeric
+// and string attributes become nominal, with values registered as they
+// are read. Values of nominal attributes must be declared. Both dense and
+// sparse data rows are supported, an unquoted "?" denotes missing values.
+// Optional instance weights, e.g. "1.2, a, {0.5}", are stored under the
+// @weight key. Lines may be up to 64MB long.
+// See https://www.cs.waikato.ac.nz/ml/weka/arff.html.
+type ARFFReader struct {
+	// Relation is the relation name
+	Relation string
+
+	model  *Model
+	attrs  []*Attribute
+	values []map[string]struct{} // declared nominal values, by attribute
+
+	scanner *bufio.Scanner
+	lineNo  int
+
+	inst MapInstance
+	err  error
+}
+
+// NewARFFReader reads the header of an ARFF source and selects
+// the target attribute by name. If target is blank, the last
+// attribute is used, as is common practice.
+func NewARFFReader(r io.Reader, target string) (*ARFFReader, error) {
+	return newARFFReader(r, func(attrs []*Attribute) (int, error) {
+		if target == "" {
+			return len(attrs) - 1, nil
+		}
+		for i, attr := range attrs {
+			if attr.Name == target {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("core: ARFF target attribute %q not found", target)
+	})
+}
+
+// NewARFFReaderAt reads the header of an ARFF source and selects the
+// target attribute by its zero-based position. Negative positions are
+// relative to the end, i.e. -1 selects the last attribute.
+func NewARFFReaderAt(r io.Reader, position int) (*ARFFReader, error) {
+	return newARFFReader(r, func(attrs []*Attribute) (int, error) {
+		if position < 0 {
+			position += len(attrs)
+		}
+		if position < 0 || position >= len(attrs) {
+			return -1, fmt.Errorf("core: ARFF target position %d out of range", position)
+		}
+		return position, nil
+	})
+}
+
+func newARFFReader(r io.Reader, selectTarget func([]*Attribute) (int, error)) (*ARFFReader, error) {
+	a := &ARFFReader{scanner: bufio.NewScanner(r)}
+	a.scanner.Buffer(nil, arffMaxLineSize)
+	if err := a.readHeader(); err != nil {
+		return nil, err
+	}
+	if len(a.attrs) < 2 {
+		return nil, fmt.Errorf("core: ARFF requires at least two attributes, %d given", len(a.attrs))
+	}
+
+	pos, err := selectTarget(a.attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	predictors := make([]*Attribute, 0, len(a.attrs)-1)
+	predictors = append(predictors, a.attrs[:pos]...)
+	predictors = append(predictors, a.attrs[pos+1:]...)
+	a.model = NewModel(a.attrs[pos], predictors[0], predictors[1:]...)
+	return a, nil
+}
+
+// Model returns the model defined by the header
+func (a *ARFFReader) Model() *Model { return a.model }
+
+// Next advances to the next data row, returns false when
+// exhausted or on errors
+func (a *ARFFReader) Next() bool {
+	if a.err != nil {
+		return false
+	}
+
+	line, ok := a.nextLine()
+	if !ok {
+		return false
+	}
+
+	a.inst = make(MapInstance, len(a.attrs))
+	if strings.HasPrefix(line, "{") {
+		a.err = a.parseSparse(line)
+	} else {
+		a.err = a.parseDense(line)
+	}
+	if a.err != nil {
+		a.err = fmt.Errorf("core: ARFF line %d: %v", a.lineNo, a.err)
+		return false
+	}
+	return true
+}
+
+// Instance returns the current instance
+func (a *ARFFReader) Instance() Instance { return a.inst }
+
+// Err returns the first error encountered
+func (a *ARFFReader) Err() error {
+	if a.err == io.EOF {
+		return nil
+	}
+	return a.err
+}
+
+// nextLine returns the next non-empty, non-comment line
+func (a *ARFFReader) nextLine() (string, bool) {
+	for a.scanner.Scan() {
+		a.lineNo++
+
+		line := strings.TrimSpace(a.scanner.Text())
+		if line == "" || line[0] == '%' {
+			continue
+		}
+		return line, true
+	}
+
+	if a.err = a.scanner.Err(); a.err == nil {
+		a.err = io.EOF
+	}
+	return "", false
+}
+
+func (a *ARFFReader) readHeader() error {
+	for {
+		line, ok := a.nextLine()
+		if !ok {
+			if a.err == io.EOF {
+				return fmt.Errorf("core: ARFF @data section not found")
+			}
+			return a.err
+		}
+
+		keyword, rest := cutToken(line)
+		switch strings.ToLower(keyword) {
+		case "@relation":
+			name, _ := cutToken(rest)
+			a.Relation = unquoteARFF(name)
+		case "@attribute":
+			attr, declared, err := parseARFFAttribute(rest)
+			if err != nil {
+				return fmt.Errorf("core: ARFF line %d: %v", a.lineNo, err)
+			}
+			a.attrs = append(a.attrs, attr)
+			a.values = append(a.values, declared)
+		case "@data":
+			return nil
+		default:
+			return fmt.Errorf("core: ARFF line %d: unexpected %q", a.lineNo, keyword)
+		}
+	}
+}
+
+func (a *ARFFReader) parseDense(line string) error {
+	fields, err := splitARFF(line)
+	if err != nil {
+		return err
+	}
+
+	if n := len(fields); n == len(a.attrs)+1 && a.isWeight(fields[n-1]) {
+		if err := a.parseWeight(fields[n-1]); err != nil {
+			return err
+		}
+		fields = fields[:n-1]
+	}
+	if len(fields) != len(a.attrs) {
+		return fmt.Errorf("expected %d values, got %d", len(a.attrs), len(fields))
+	}
+
+	for i, field := range fields {
+		if err := a.set(i, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *ARFFReader) parseSparse(line string) error {
+	end := closingBrace(line)
+	if end < 0 {
+		return fmt.Errorf("unterminated sparse row")
+	}
+	if rest := strings.TrimSpace(strings.TrimPrefix(line[end+1:], ",")); rest != "" {
+		if err := a.parseWeight(rest); err != nil {
+			return err
+		}
+	}
+
+	// Omitted values are zero or the first nominal value
+	for _, attr := range a.attrs {
+		if attr.IsNominal() {
+			if vals := attr.Values.Values(); len(vals) != 0 {
+				a.inst[attr.Name] = vals[0]
+			}
+		} else {
+			a.inst[attr.Name] = 0.0
+		}
+	}
+
+	fields, err := splitARFF(line[1:end])
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+
+		index, value := cutToken(field)
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 || i >= len(a.attrs) {
+			return fmt.Errorf("invalid sparse index %q", index)
+		}
+		if err := a.set(i, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set sets the value of the i-th attribute from a raw, possibly quoted field
+func (a *ARFFReader) set(i int, field string) error {
+	attr := a.attrs[i]
+	if field == "?" {
+		delete(a.inst, attr.Name)
+		return nil
+	}
+
+	value := unquoteARFF(field)
+	if attr.IsNominal() {
+		if declared := a.values[i]; declared != nil {
+			if _, ok := declared[value]; !ok {
+				return fmt.Errorf("unknown nominal value %q for %q", value, attr.Name)
+			}
+		}
+		a.inst[attr.Name] = value
+		return nil
+	}
+
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid numeric value %q for %q", value, attr.Name)
+	}
+	a.inst[attr.Name] = num
+	return nil
+}
+
+func (a *ARFFReader) isWeight(field string) bool {
+	field = strings.TrimSpace(field)
+	if !strings.HasPrefix(field, "{") || !strings.HasSuffix(field, "}") {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(field[1:len(field)-1]), 64)
+	return err == nil
+}
+
+func (a *ARFFReader) parseWeight(field string) error {
+	field = strings.TrimSpace(field)
+	if !a.isWeight(field) {
+		return fmt.Errorf("invalid instance weight %q", field)
+	}
+
+	weight, _ := strconv.ParseFloat(strings.TrimSpace(field[1:len(field)-1]), 64)
+	a.inst["@weight"] = weight
+	return nil
+}
+
+// --------------------------------------------------------------------
+
+// parseARFFAttribute parses an attribute definition, returns the attribute
+// and the set of declared values for nominal attributes
+func parseARFFAttribute(def string) (*Attribute, map[string]struct{}, error) {
+	name, kind := cutToken(def)
+	if name == "" || kind == "" {
+		return nil, nil, fmt.Errorf("invalid attribute definition %q", def)
+	}
+	attr := &Attribute{Name: unquoteARFF(name)}
+
+	if strings.HasPrefix(kind, "{") {
+		end := strings.LastIndex(kind, "}")
+		if end < 0 {
+			return nil, nil, fmt.Errorf("unterminated nominal values for %q", attr.Name)
+		}
+
+		fields, err := splitARFF(kind[1:end])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		values := make([]string, len(fields))
+		declared := make(map[string]struct{}, len(fields))
+		for i, field := range fields {
+			values[i] = unquoteARFF(field)
+			declared[values[i]] = struct{}{}
+		}
+		attr.Kind = AttributeKindNominal
+		attr.Values = NewAttributeValues(values...)
+		return attr, declared, nil
+	}
+
+	kind, _ = cutToken(kind)
+	switch strings.ToLower(kind) {
+	case "numeric", "real", "integer":
+		attr.Kind = AttributeKindNumeric
+	case "string":
+		attr.Kind = AttributeKindNominal
+		attr.Values = NewAttributeValues()
+	default:
+		return nil, nil, fmt.Errorf("unsupported type %q for %q", kind, attr.Name)
+	}
+	return attr, nil, nil
+}
+
+// cutToken cuts the first whitespace-separated, optionally
+// quoted token from s, returns the token and the trimmed rest
+func cutToken(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ""
+	}
+
+	if q := s[0]; q == '\'' || q == '"' {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == q {
+				return s[:i+1], strings.TrimSpace(s[i+1:])
+			}
+		}
+		return s, ""
+	}
+
+	if i := strings.IndexAny(s, " \t"); i > -1 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// closingBrace returns the index of the first closing
+// brace in s which is not quoted, -1 if not found
+func closingBrace(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitARFF splits a comma-separated list of optionally quoted values,
+// fields are trimmed but not unquoted
+func splitARFF(s string) ([]string, error) {
+	var fields []string
+	var quote byte
+
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			fields = append(fields, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(fields) != 0 {
+		fields = append(fields, last)
+	}
+	return fields, nil
+}
+
+// unquoteARFF trims and unquotes a value
+func unquoteARFF(s string) string {
+	s = strings.TrimSpace(s)
+	if n := len(s); n < 2 || (s[0] != '\'' && s[0] != '"') || s[n-1] != s[0] {
+		return s
+	}
+
+	buf := make([]byte, 0, len(s)-2)
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+		}
+		buf = append(buf, s[i])
+	}
+	return string(buf)
+}
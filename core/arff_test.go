@@ -0,0 +1,149 @@
+package core
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ARFFReader", func() {
+	const weather = `% The weather dataset
+@RELATION weather
+
+@ATTRIBUTE outlook {sunny, overcast, rainy}
+@ATTRIBUTE temperature REAL
+@ATTRIBUTE humidity numeric
+@ATTRIBUTE 'is windy' {TRUE, FALSE}
+@ATTRIBUTE play {yes, no}
+
+@DATA
+sunny,85,85,FALSE,no
+overcast, 83, ?, 'FALSE', yes
+% comment
+rainy,70,96,FALSE,yes,{0.5}
+`
+
+	const sparse = `@relation sparse
+@attribute a numeric
+@attribute b {x, y, z}
+@attribute c string
+@attribute d numeric
+@data
+{0 1.5, 2 'hello world'}
+{1 z, 3 ?}, {2}
+`
+
+	readAll := func(r *ARFFReader) []Instance {
+		var insts []Instance
+		for r.Next() {
+			insts = append(insts, r.Instance())
+		}
+		Expect(r.Err()).NotTo(HaveOccurred())
+		return insts
+	}
+
+	It("should parse headers", func() {
+		r, err := NewARFFReader(strings.NewReader(weather), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation).To(Equal("weather"))
+
+		model := r.Model()
+		Expect(model.Target().Name).To(Equal("play"))
+		Expect(model.Target().Values.Values()).To(Equal([]string{"yes", "no"}))
+		Expect(model.IsClassification()).To(BeTrue())
+		Expect(model.NumPredictors()).To(Equal(4))
+		Expect(model.Predictor("outlook").Values.Values()).To(Equal([]string{"sunny", "overcast", "rainy"}))
+		Expect(model.Predictor("temperature").Kind).To(Equal(AttributeKindNumeric))
+		Expect(model.Predictor("humidity").Kind).To(Equal(AttributeKindNumeric))
+		Expect(model.Predictor("is windy").Kind).To(Equal(AttributeKindNominal))
+	})
+
+	It("should select targets by name or position", func() {
+		r, err := NewARFFReader(strings.NewReader(weather), "temperature")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Model().Target().Name).To(Equal("temperature"))
+		Expect(r.Model().IsRegression()).To(BeTrue())
+		Expect(r.Model().Predictor("play")).NotTo(BeNil())
+
+		r, err = NewARFFReaderAt(strings.NewReader(weather), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Model().Target().Name).To(Equal("outlook"))
+
+		r, err = NewARFFReaderAt(strings.NewReader(weather), -2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Model().Target().Name).To(Equal("is windy"))
+
+		_, err = NewARFFReader(strings.NewReader(weather), "unknown")
+		Expect(err).To(MatchError(`core: ARFF target attribute "unknown" not found`))
+		_, err = NewARFFReaderAt(strings.NewReader(weather), 5)
+		Expect(err).To(MatchError(`core: ARFF target position 5 out of range`))
+	})
+
+	It("should read dense rows", func() {
+		r, err := NewARFFReader(strings.NewReader(weather), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(r)).To(Equal([]Instance{
+			MapInstance{"outlook": "sunny", "temperature": 85.0, "humidity": 85.0, "is windy": "FALSE", "play": "no"},
+			MapInstance{"outlook": "overcast", "temperature": 83.0, "is windy": "FALSE", "play": "yes"},
+			MapInstance{"outlook": "rainy", "temperature": 70.0, "humidity": 96.0, "is windy": "FALSE", "play": "yes", "@weight": 0.5},
+		}))
+
+		humidity := r.Model().Predictor("humidity")
+		inst := MapInstance{"outlook": "overcast"}
+		Expect(humidity.Value(inst).IsMissing()).To(BeTrue())
+	})
+
+	It("should read sparse rows", func() {
+		r, err := NewARFFReader(strings.NewReader(sparse), "b")
+		Expect(err).NotTo(HaveOccurred())
+
+		insts := readAll(r)
+		Expect(insts).To(Equal([]Instance{
+			MapInstance{"a": 1.5, "b": "x", "c": "hello world", "d": 0.0},
+			MapInstance{"a": 0.0, "b": "z", "@weight": 2.0},
+		}))
+		Expect(insts[1].GetInstanceWeight()).To(Equal(2.0))
+		Expect(r.Model().Predictor("c").Value(insts[0])).To(Equal(AttributeValue(0)))
+	})
+
+	It("should read quoted question marks as values", func() {
+		r, err := NewARFFReader(strings.NewReader("@attribute a string\n@attribute b {'?', x}\n@data\n'?','?'\n?,?\n{0 '?', 1 ?}\n"), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(r)).To(Equal([]Instance{
+			MapInstance{"a": "?", "b": "?"},
+			MapInstance{},
+			MapInstance{"a": "?"},
+		}))
+	})
+
+	It("should read long rows", func() {
+		long := strings.Repeat("x", 100000)
+		r, err := NewARFFReader(strings.NewReader("@attribute a string\n@attribute b numeric\n@data\n"+long+",1\n"), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(r)).To(Equal([]Instance{
+			MapInstance{"a": long, "b": 1.0},
+		}))
+	})
+
+	It("should fail on invalid input", func() {
+		_, err := NewARFFReader(strings.NewReader("@relation x\n@attribute a date\n@data\n"), "")
+		Expect(err).To(MatchError(`core: ARFF line 2: unsupported type "date" for "a"`))
+
+		_, err = NewARFFReader(strings.NewReader("@relation x\n@attribute a numeric\n"), "")
+		Expect(err).To(MatchError(`core: ARFF @data section not found`))
+
+		r, err := NewARFFReader(strings.NewReader("@attribute a numeric\n@attribute b {x,y}\n@data\n1,x\nfoo,y\n"), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Next()).To(BeFalse())
+		Expect(r.Err()).To(MatchError(`core: ARFF line 5: invalid numeric value "foo" for "a"`))
+
+		r, err = NewARFFReader(strings.NewReader("@attribute a numeric\n@attribute c {a,b}\n@data\n1,a\n1,zz\n"), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Next()).To(BeFalse())
+		Expect(r.Err()).To(MatchError(`core: ARFF line 5: unknown nominal value "zz" for "c"`))
+		Expect(r.Model().Target().Values.Values()).To(Equal([]string{"a", "b"}))
+	})
+})
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"sync"
@@ -33,6 +34,28 @@ func (k AttributeKind) String() string {
 	return fmt.Sprintf("unknown (%d)", k)
 }
 
+// MarshalText implements encoding.TextMarshaler
+func (k AttributeKind) MarshalText() ([]byte, error) {
+	switch k {
+	case AttributeKindNumeric, AttributeKindNominal:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("core: unknown attribute kind %d", k)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (k *AttributeKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "numeric":
+		*k = AttributeKindNumeric
+	case "nominal":
+		*k = AttributeKindNominal
+	default:
+		return fmt.Errorf("core: unknown attribute kind %q", text)
+	}
+	return nil
+}
+
 // AttributeValue is an attribute value extracted from an instance
 type AttributeValue float64
 
@@ -157,6 +180,42 @@ func (a *Attribute) DecodeFrom(dec *msgpack.Decoder) error {
 	return nil
 }
 
+// attributeJSON is the JSON representation of an Attribute
+type attributeJSON struct {
+	Name   string           `json:"name"`
+	Kind   *AttributeKind   `json:"kind"`
+	Values *AttributeValues `json:"values,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *Attribute) MarshalJSON() ([]byte, error) {
+	v := attributeJSON{Name: a.Name, Kind: &a.Kind}
+	if a.IsNominal() && a.Values.Len() != 0 {
+		v.Values = a.Values
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Attribute) UnmarshalJSON(data []byte) error {
+	var v attributeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Name == "" {
+		return fmt.Errorf("core: attribute name missing")
+	}
+	if v.Kind == nil {
+		return fmt.Errorf("core: attribute %q kind missing", v.Name)
+	}
+	if *v.Kind == AttributeKindNumeric && v.Values.Len() != 0 {
+		return fmt.Errorf("core: numeric attribute %q must not have values", v.Name)
+	}
+
+	a.Name, a.Kind, a.Values = v.Name, *v.Kind, v.Values
+	return nil
+}
+
 // --------------------------------------------------------------------
 
 // AttributeValues hold a slice of possible values
@@ -258,3 +317,37 @@ func (v *AttributeValues) DecodeFrom(dec *msgpack.Decoder) error {
 
 	return dec.Decode(&v.vi)
 }
+
+// MarshalJSON implements json.Marshaler
+func (v *AttributeValues) MarshalJSON() ([]byte, error) {
+	v.mu.RLock()
+	vals := make([]string, len(v.vi))
+	for val, i := range v.vi {
+		vals[i] = val
+	}
+	v.mu.RUnlock()
+
+	return json.Marshal(vals)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *AttributeValues) UnmarshalJSON(data []byte) error {
+	var vals []string
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+
+	vi := make(map[string]int, len(vals))
+	for i, val := range vals {
+		if _, ok := vi[val]; ok {
+			return fmt.Errorf("core: duplicate attribute value %q", val)
+		}
+		vi[val] = i
+	}
+
+	v.mu.Lock()
+	v.vi = vi
+	v.vals = nil
+	v.mu.Unlock()
+	return nil
+}
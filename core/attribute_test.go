@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 	"math"
 
 	"github.com/bsm/reason/internal/msgpack"
@@ -56,6 +57,28 @@ var _ = Describe("Attribute", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(out).To(Equal(nominal))
 	})
+
+	It("should marshal/unmarshal JSON", func() {
+		nominal.Values = NewAttributeValues("b", "a")
+
+		data, err := json.Marshal([]*Attribute{nominal, numeric})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`[{"name":"cat","kind":"nominal","values":["b","a"]},{"name":"num","kind":"numeric"}]`))
+
+		var out []*Attribute
+		Expect(json.Unmarshal(data, &out)).To(Succeed())
+		Expect(out).To(Equal([]*Attribute{nominal, numeric}))
+		Expect(out[0].ValueOf("a")).To(Equal(AttributeValue(1)))
+	})
+
+	It("should validate JSON", func() {
+		Expect(json.Unmarshal([]byte(`{"kind":"numeric"}`), new(Attribute))).To(MatchError(`core: attribute name missing`))
+		Expect(json.Unmarshal([]byte(`{"name":"x"}`), new(Attribute))).To(MatchError(`core: attribute "x" kind missing`))
+		Expect(json.Unmarshal([]byte(`{"name":"x","kind":null}`), new(Attribute))).To(MatchError(`core: attribute "x" kind missing`))
+		Expect(json.Unmarshal([]byte(`{"name":"x","kind":"date"}`), new(Attribute))).To(MatchError(`core: unknown attribute kind "date"`))
+		Expect(json.Unmarshal([]byte(`{"name":"x","kind":"numeric","values":["a"]}`), new(Attribute))).To(MatchError(`core: numeric attribute "x" must not have values`))
+		Expect(json.Unmarshal([]byte(`{"name":"x","kind":"nominal","values":["a","a"]}`), new(Attribute))).To(MatchError(`core: duplicate attribute value "a"`))
+	})
 })
 
 var _ = Describe("AttributeValue", func() {
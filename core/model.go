@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/bsm/reason/internal/msgpack"
 )
@@ -75,6 +77,47 @@ func (m *Model) DecodeFrom(dec *msgpack.Decoder) error {
 	return nil
 }
 
+// modelJSON is the JSON representation of a Model
+type modelJSON struct {
+	Target     *Attribute   `json:"target"`
+	Predictors []*Attribute `json:"predictors"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Model) MarshalJSON() ([]byte, error) {
+	return json.Marshal(modelJSON{Target: m.target, Predictors: m.predictors})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var v modelJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Target == nil {
+		return fmt.Errorf("core: model target missing")
+	}
+	if len(v.Predictors) == 0 {
+		return fmt.Errorf("core: model requires at least one predictor")
+	}
+
+	seen := map[string]bool{v.Target.Name: true}
+	for _, attr := range v.Predictors {
+		if attr == nil {
+			return fmt.Errorf("core: model contains a blank predictor")
+		}
+		if seen[attr.Name] {
+			return fmt.Errorf("core: duplicate model attribute %q", attr.Name)
+		}
+		seen[attr.Name] = true
+	}
+
+	m.target = v.Target
+	m.predictors = v.Predictors
+	m.postInit()
+	return nil
+}
+
 func (m *Model) postInit() {
 	m.lookup = make(map[string]int, len(m.predictors))
 	for i, attr := range m.predictors {
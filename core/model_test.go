@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 
 	"github.com/bsm/reason/internal/msgpack"
 
@@ -52,4 +53,27 @@ var _ = Describe("Model", func() {
 		Expect(out).To(Equal(subject))
 		Expect(dec.Context().Value(ModelContextKey)).To(Equal(out))
 	})
+
+	It("should marshal/unmarshal JSON", func() {
+		data, err := json.Marshal(subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{
+			"target": {"name": "season", "kind": "nominal", "values": ["winter", "spring", "summer", "autumn"]},
+			"predictors": [
+				{"name": "temperature", "kind": "numeric"},
+				{"name": "humidity", "kind": "numeric"}
+			]
+		}`))
+
+		out := new(Model)
+		Expect(json.Unmarshal(data, out)).To(Succeed())
+		Expect(out).To(Equal(subject))
+		Expect(out.Predictor("humidity").Name).To(Equal("humidity"))
+	})
+
+	It("should validate JSON", func() {
+		Expect(json.Unmarshal([]byte(`{"predictors":[{"name":"x","kind":"numeric"}]}`), new(Model))).To(MatchError(`core: model target missing`))
+		Expect(json.Unmarshal([]byte(`{"target":{"name":"y","kind":"numeric"}}`), new(Model))).To(MatchError(`core: model requires at least one predictor`))
+		Expect(json.Unmarshal([]byte(`{"target":{"name":"y","kind":"numeric"},"predictors":[{"name":"y","kind":"numeric"}]}`), new(Model))).To(MatchError(`core: duplicate model attribute "y"`))
+	})
 })
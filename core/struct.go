@@ -0,0 +1,338 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StructInstance represents a struct as an instance. Struct fields are mapped
+// to attributes via tags, e.g.:
+//
+//	type Weather struct {
+//		Outlook string   `reason:"outlook"`
+//		Temp    float64  `reason:"temperature"`
+//		Zip     int      `reason:"zip,nominal"`
+//		Play    bool     `reason:"play"`
+//		Weight  *float64 `reason:"@weight"`
+//		Notes   string   // not mapped
+//	}
+//
+// Untagged fields and fields tagged with "-" are ignored, fields of embedded
+// structs are included. Strings and bools are nominal, integers and floats are
+// numeric by default. The kind can be overridden by a "nominal" or "numeric"
+// tag option, where numeric bools are 1 or 0. Nil pointer fields are missing.
+// The @weight tag marks the field containing the instance weight, which must
+// be a pointer to a number, so that weights default to 1.0 if nil.
+type StructInstance struct {
+	v reflect.Value
+	t *structType
+}
+
+// NewStructInstance wraps a struct or a pointer to a struct.
+// Field mappings are cached per type. Returns an error if v is not
+// a struct or if the field mappings are invalid.
+func NewStructInstance(v interface{}) (StructInstance, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return StructInstance{}, fmt.Errorf("core: expected a struct, but got %#v", v)
+	}
+
+	t, err := lookupStructType(rv.Type())
+	if err != nil {
+		return StructInstance{}, err
+	}
+	return StructInstance{v: rv, t: t}, nil
+}
+
+// MustStructInstance wraps a struct or a pointer to a struct, like
+// NewStructInstance, but panics on errors. Use StructModel to validate
+// types upfront.
+func MustStructInstance(v interface{}) StructInstance {
+	s, err := NewStructInstance(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// GetAttributeValue implements Instance
+func (s StructInstance) GetAttributeValue(name string) InstanceValue {
+	f, ok := s.t.lookup[name]
+	if !ok {
+		return nil
+	}
+
+	fv, ok := structField(s.v, f.Index)
+	if !ok {
+		return nil
+	}
+	return f.valueOf(fv)
+}
+
+// GetInstanceWeight implements Instance
+func (s StructInstance) GetInstanceWeight() float64 {
+	if s.t.weight == nil {
+		return 1.0
+	}
+	if fv, ok := structField(s.v, s.t.weight.Index); ok {
+		return numericField(fv)
+	}
+	return 1.0
+}
+
+// StructModel derives a model from the mapped fields of a struct type, in the
+// order of their declaration. It accepts structs or pointers to structs, nil
+// pointers are allowed. The target is selected by attribute name or, if blank,
+// is the last mapped field. Nominal bools are initialised with "false" and
+// "true" as values.
+func StructModel(v interface{}, target string) (*Model, error) {
+	rt := reflect.TypeOf(v)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	t, err := lookupStructType(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	var attrs []*Attribute
+	pos := -1
+	for _, f := range t.fields {
+		if target == "" || f.Name == target {
+			pos = len(attrs)
+		}
+		attrs = append(attrs, f.Attribute())
+	}
+	if pos < 0 {
+		return nil, fmt.Errorf("core: target attribute %q not found in %s", target, rt)
+	}
+	if len(attrs) < 2 {
+		return nil, fmt.Errorf("core: at least two mapped fields required in %s, %d found", rt, len(attrs))
+	}
+
+	predictors := make([]*Attribute, 0, len(attrs)-1)
+	predictors = append(predictors, attrs[:pos]...)
+	predictors = append(predictors, attrs[pos+1:]...)
+	return NewModel(attrs[pos], predictors[0], predictors[1:]...), nil
+}
+
+// --------------------------------------------------------------------
+
+var structTypes = struct {
+	m  map[reflect.Type]*structType
+	mu sync.RWMutex
+}{m: make(map[reflect.Type]*structType)}
+
+// lookupStructType returns the (cached) mappings of a struct type
+func lookupStructType(rt reflect.Type) (*structType, error) {
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("core: expected a struct, but got %v", rt)
+	}
+
+	structTypes.mu.RLock()
+	t, ok := structTypes.m[rt]
+	structTypes.mu.RUnlock()
+	if ok {
+		return t, t.err
+	}
+
+	t = newStructType(rt)
+
+	structTypes.mu.Lock()
+	structTypes.m[rt] = t
+	structTypes.mu.Unlock()
+	return t, t.err
+}
+
+// structType holds the field mappings of a struct type
+type structType struct {
+	fields []*structTypeField
+	lookup map[string]*structTypeField
+	weight *structTypeField
+	err    error
+}
+
+func newStructType(rt reflect.Type) *structType {
+	t := &structType{lookup: make(map[string]*structTypeField)}
+	t.err = t.scan(rt, nil, map[reflect.Type]struct{}{rt: {}})
+	return t
+}
+
+// scan maps the fields of rt, including the fields of embedded structs.
+// Embedded structs which are already being scanned, i.e. which embed
+// themselves directly or indirectly, are skipped.
+func (t *structType) scan(rt reflect.Type, index []int, scanning map[reflect.Type]struct{}) error {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("reason")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if !ok {
+			if ft := sf.Type; sf.Anonymous {
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if _, ok := scanning[ft]; !ok && ft.Kind() == reflect.Struct {
+					scanning[ft] = struct{}{}
+					err := t.scan(ft, fieldIndex, scanning)
+					delete(scanning, ft)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("core: field %s.%s is unexported", rt, sf.Name)
+		}
+
+		f, err := newStructTypeField(sf, tag, fieldIndex)
+		if err != nil {
+			return fmt.Errorf("core: field %s.%s: %v", rt, sf.Name, err)
+		}
+
+		if f.Name == "@weight" {
+			if t.weight != nil || f.Kind != AttributeKindNumeric || sf.Type.Kind() != reflect.Ptr {
+				return fmt.Errorf("core: field %s.%s is not a valid weight, expected a pointer to a number", rt, sf.Name)
+			}
+			t.weight = f
+			continue
+		}
+
+		if _, ok := t.lookup[f.Name]; ok {
+			return fmt.Errorf("core: field %s.%s: duplicate attribute %q", rt, sf.Name, f.Name)
+		}
+		t.lookup[f.Name] = f
+		t.fields = append(t.fields, f)
+	}
+	return nil
+}
+
+// structTypeField is a mapped struct field
+type structTypeField struct {
+	Name  string
+	Kind  AttributeKind
+	Index []int
+
+	typ reflect.Type // the dereferenced field type
+}
+
+func newStructTypeField(sf reflect.StructField, tag string, index []int) (*structTypeField, error) {
+	opts := strings.Split(tag, ",")
+	f := &structTypeField{Name: opts[0], Index: index, typ: sf.Type}
+	if f.Name == "" {
+		f.Name = sf.Name
+	}
+	if f.typ.Kind() == reflect.Ptr {
+		f.typ = f.typ.Elem()
+	}
+
+	switch f.typ.Kind() {
+	case reflect.String, reflect.Bool:
+		f.Kind = AttributeKindNominal
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f.Kind = AttributeKindNumeric
+	default:
+		return nil, fmt.Errorf("unsupported type %s", sf.Type)
+	}
+
+	for _, opt := range opts[1:] {
+		switch opt {
+		case "nominal":
+			f.Kind = AttributeKindNominal
+		case "numeric":
+			if f.typ.Kind() == reflect.String {
+				return nil, fmt.Errorf("unsupported type %s for numeric attributes", sf.Type)
+			}
+			f.Kind = AttributeKindNumeric
+		default:
+			return nil, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+	return f, nil
+}
+
+// Attribute returns a new attribute for the field
+func (f *structTypeField) Attribute() *Attribute {
+	attr := &Attribute{Name: f.Name, Kind: f.Kind}
+	if f.Kind == AttributeKindNominal {
+		if f.typ.Kind() == reflect.Bool {
+			attr.Values = NewAttributeValues("false", "true")
+		} else {
+			attr.Values = NewAttributeValues()
+		}
+	}
+	return attr
+}
+
+// valueOf returns the instance value of a (dereferenced) field value
+func (f *structTypeField) valueOf(fv reflect.Value) InstanceValue {
+	if f.Kind == AttributeKindNumeric {
+		return numericField(fv)
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String()
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 64)
+	}
+	return nil
+}
+
+// numericField returns the numeric value of a (dereferenced) field value
+func numericField(fv reflect.Value) float64 {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fv.Float()
+	case reflect.Bool:
+		if fv.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
+
+// structField returns the dereferenced field value at index, returns
+// false if the field or any of the embedded structs is a nil pointer.
+func structField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"strconv"
+	"testing"
+)
+
+type benchStruct struct {
+	Outlook  string   `reason:"outlook"`
+	Temp     float64  `reason:"temperature"`
+	Humidity float64  `reason:"humidity"`
+	Windy    bool     `reason:"windy"`
+	Play     bool     `reason:"play"`
+	Weight   *float64 `reason:"@weight"`
+}
+
+func BenchmarkStructInstance(b *testing.B) {
+	model, err := StructModel(benchStruct{}, "play")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rec := &benchStruct{Outlook: "sunny", Temp: 85, Humidity: 85, Windy: true}
+	benchmarkInstance(b, model, func() Instance { return MustStructInstance(rec) })
+}
+
+func BenchmarkMapInstance(b *testing.B) {
+	model, err := StructModel(benchStruct{}, "play")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rec := &benchStruct{Outlook: "sunny", Temp: 85, Humidity: 85, Windy: true}
+	benchmarkInstance(b, model, func() Instance {
+		return MapInstance{
+			"outlook":     rec.Outlook,
+			"temperature": rec.Temp,
+			"humidity":    rec.Humidity,
+			"windy":       strconv.FormatBool(rec.Windy),
+			"play":        strconv.FormatBool(rec.Play),
+		}
+	})
+}
+
+func benchmarkInstance(b *testing.B, model *Model, newInstance func() Instance) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		inst := newInstance()
+		model.Target().Value(inst)
+		for _, attr := range model.Predictors() {
+			attr.Value(inst)
+		}
+		inst.GetInstanceWeight()
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type mockStructBase struct {
+	Station string   `reason:"station"`
+	Weight  *float32 `reason:"@weight"`
+}
+
+type mockStruct struct {
+	mockStructBase
+
+	Outlook  string   `reason:"outlook"`
+	Temp     float64  `reason:"temperature"`
+	Humidity *int     `reason:"humidity"`
+	Zip      uint16   `reason:"zip,nominal"`
+	Windy    bool     `reason:"windy,numeric"`
+	Play     bool     `reason:"play"`
+	Notes    string   `reason:"-"`
+	Ignored  []string // not mapped
+}
+
+type mockCyclic struct {
+	*mockCyclic
+	*mockCyclicB
+
+	Value float64 `reason:"value"`
+}
+
+type mockCyclicB struct {
+	*mockCyclic
+
+	Name string `reason:"name"`
+}
+
+var _ = Describe("StructInstance", func() {
+	humidity, weight := 85, float32(0.5)
+
+	It("should extract values", func() {
+		subject := MustStructInstance(&mockStruct{
+			mockStructBase: mockStructBase{Station: "north", Weight: &weight},
+			Outlook:        "sunny",
+			Temp:           85.5,
+			Humidity:       &humidity,
+			Zip:            10115,
+			Windy:          true,
+			Notes:          "hot",
+		})
+
+		Expect(subject.GetAttributeValue("station")).To(Equal("north"))
+		Expect(subject.GetAttributeValue("outlook")).To(Equal("sunny"))
+		Expect(subject.GetAttributeValue("temperature")).To(Equal(85.5))
+		Expect(subject.GetAttributeValue("humidity")).To(Equal(85.0))
+		Expect(subject.GetAttributeValue("zip")).To(Equal("10115"))
+		Expect(subject.GetAttributeValue("windy")).To(Equal(1.0))
+		Expect(subject.GetAttributeValue("play")).To(Equal("false"))
+		Expect(subject.GetAttributeValue("Notes")).To(BeNil())
+		Expect(subject.GetAttributeValue("Ignored")).To(BeNil())
+		Expect(subject.GetAttributeValue("unknown")).To(BeNil())
+		Expect(subject.GetInstanceWeight()).To(Equal(0.5))
+	})
+
+	It("should treat nil pointers as missing", func() {
+		subject := MustStructInstance(mockStruct{})
+		Expect(subject.GetAttributeValue("humidity")).To(BeNil())
+		Expect(subject.GetAttributeValue("temperature")).To(Equal(0.0))
+		Expect(subject.GetInstanceWeight()).To(Equal(1.0))
+
+		type noWeight struct {
+			*mockStructBase `reason:"-"`
+			Base            *mockStructBase
+			Temp            float64 `reason:"temperature"`
+		}
+		subject = MustStructInstance(noWeight{Temp: 3})
+		Expect(subject.GetAttributeValue("temperature")).To(Equal(3.0))
+		Expect(subject.GetInstanceWeight()).To(Equal(1.0))
+
+		type embedded struct {
+			*mockStructBase
+			Temp float64 `reason:"temperature"`
+		}
+		subject = MustStructInstance(embedded{Temp: 3})
+		Expect(subject.GetAttributeValue("station")).To(BeNil())
+		Expect(subject.GetInstanceWeight()).To(Equal(1.0))
+	})
+
+	It("should work with attributes", func() {
+		model, err := StructModel((*mockStruct)(nil), "play")
+		Expect(err).NotTo(HaveOccurred())
+
+		inst := MustStructInstance(&mockStruct{Outlook: "rainy", Temp: 12, Play: true})
+		Expect(model.Target().Value(inst)).To(Equal(AttributeValue(1)))
+		Expect(model.Predictor("outlook").Value(inst)).To(Equal(AttributeValue(0)))
+		Expect(model.Predictor("temperature").Value(inst)).To(Equal(AttributeValue(12)))
+		Expect(model.Predictor("humidity").Value(inst).IsMissing()).To(BeTrue())
+	})
+
+	It("should fail on invalid types", func() {
+		_, err := NewStructInstance(3)
+		Expect(err).To(MatchError(`core: expected a struct, but got int`))
+		_, err = NewStructInstance(nil)
+		Expect(err).To(MatchError(`core: expected a struct, but got <nil>`))
+		_, err = NewStructInstance((*mockStruct)(nil))
+		Expect(err).To(MatchError(`core: expected a struct, but got (*core.mockStruct)(nil)`))
+		_, err = NewStructInstance(struct {
+			A map[string]int `reason:"a"`
+		}{})
+		Expect(err).To(MatchError(ContainSubstring(`.A: unsupported type map[string]int`)))
+
+		Expect(func() { MustStructInstance(3) }).To(Panic())
+	})
+
+	It("should support self-embedding structs", func() {
+		subject, err := NewStructInstance(&mockCyclic{mockCyclicB: &mockCyclicB{Name: "b"}, Value: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subject.GetAttributeValue("name")).To(Equal("b"))
+		Expect(subject.GetAttributeValue("value")).To(Equal(2.0))
+
+		model, err := StructModel(mockCyclic{}, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.NumPredictors()).To(Equal(1))
+	})
+})
+
+var _ = Describe("StructModel", func() {
+
+	It("should derive models", func() {
+		model, err := StructModel(mockStruct{}, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Target()).To(Equal(&Attribute{Name: "play", Kind: AttributeKindNominal, Values: NewAttributeValues("false", "true")}))
+		Expect(model.Predictors()).To(Equal([]*Attribute{
+			{Name: "station", Kind: AttributeKindNominal, Values: NewAttributeValues()},
+			{Name: "outlook", Kind: AttributeKindNominal, Values: NewAttributeValues()},
+			{Name: "temperature", Kind: AttributeKindNumeric},
+			{Name: "humidity", Kind: AttributeKindNumeric},
+			{Name: "zip", Kind: AttributeKindNominal, Values: NewAttributeValues()},
+			{Name: "windy", Kind: AttributeKindNumeric},
+		}))
+
+		model, err = StructModel(&mockStruct{}, "temperature")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.IsRegression()).To(BeTrue())
+		Expect(model.NumPredictors()).To(Equal(6))
+	})
+
+	It("should fail on invalid types", func() {
+		_, err := StructModel(mockStruct{}, "unknown")
+		Expect(err).To(MatchError(`core: target attribute "unknown" not found in core.mockStruct`))
+
+		_, err = StructModel(nil, "")
+		Expect(err).To(MatchError(`core: expected a struct, but got <nil>`))
+
+		_, err = StructModel(struct {
+			A string `reason:"a"`
+		}{}, "")
+		Expect(err).To(MatchError(`core: at least two mapped fields required in struct { A string "reason:\"a\"" }, 1 found`))
+
+		_, err = StructModel(struct {
+			A string `reason:"a,numeric"`
+		}{}, "")
+		Expect(err).To(MatchError(`core: field struct { A string "reason:\"a,numeric\"" }.A: unsupported type string for numeric attributes`))
+
+		_, err = StructModel(struct {
+			A int `reason:"a"`
+			B int `reason:"a"`
+		}{}, "")
+		Expect(err).To(MatchError(ContainSubstring(`.B: duplicate attribute "a"`)))
+
+		_, err = StructModel(struct {
+			W *string `reason:"@weight"`
+		}{}, "")
+		Expect(err).To(MatchError(ContainSubstring(`.W is not a valid weight, expected a pointer to a number`)))
+
+		_, err = StructModel(struct {
+			W float64 `reason:"@weight"`
+		}{}, "")
+		Expect(err).To(MatchError(ContainSubstring(`.W is not a valid weight, expected a pointer to a number`)))
+
+		_, err = StructModel(struct {
+			a int `reason:"a"`
+		}{}, "")
+		Expect(err).To(MatchError(ContainSubstring(`.a is unexported`)))
+	})
+})
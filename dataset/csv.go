@@ -0,0 +1,265 @@
+// Package dataset implements readers which stream instances from
+// common data formats and infer models from sample data.
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/reason/core"
+)
+
+// CSVConfig configures CSV/TSV readers
+type CSVConfig struct {
+	// The field delimiter, use '\t' for TSV sources.
+	// Default: ','
+	Comma rune
+
+	// Indicates that the first row contains the column names.
+	// Default: false
+	Header bool
+
+	// The names of the leading columns, in order. Overrides the header
+	// row, if present. Columns with blank names are skipped, as are
+	// trailing columns and columns which neither map to an attribute of
+	// the model nor to the weight column.
+	// Default: the header row or the names of the model's predictors,
+	// followed by the name of the target
+	Columns []string
+
+	// Maps column names to attribute names.
+	// Default: nil (column names are attribute names)
+	Mapping map[string]string
+
+	// The name of the column containing instance weights.
+	// Default: "" (none)
+	WeightColumn string
+
+	// The tokens which denote missing values, compared to trimmed values.
+	// Default: []string{"", "?"}
+	MissingTokens []string
+
+	// Treat values of numeric attributes which cannot be coerced to numbers
+	// as missing instead of failing.
+	// Default: false
+	Lenient bool
+}
+
+func (c *CSVConfig) norm() {
+	if c.Comma == 0 {
+		c.Comma = ','
+	}
+	if c.MissingTokens == nil {
+		c.MissingTokens = []string{"", "?"}
+	}
+}
+
+// isMissing returns true if the trimmed value is a missing token
+func (c *CSVConfig) isMissing(s string) bool {
+	s = strings.TrimSpace(s)
+	for _, tok := range c.MissingTokens {
+		if s == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeName returns the attribute name of a column
+func (c *CSVConfig) attributeName(column string) string {
+	if name, ok := c.Mapping[column]; ok {
+		return name
+	}
+	return column
+}
+
+// open wraps a source in a CSV reader and returns the column names,
+// which are read from the header, if configured. Returns nil names if
+// the columns are neither configured nor read from a header.
+func (c *CSVConfig) open(r io.Reader) (*csv.Reader, []string, error) {
+	recs := csv.NewReader(r)
+	recs.Comma = c.Comma
+	recs.TrimLeadingSpace = true
+
+	var names []string
+	if c.Header {
+		header, err := recs.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+		names = header
+	}
+	if len(c.Columns) != 0 {
+		names = c.Columns
+		recs.FieldsPerRecord = -1
+	}
+	return recs, names, nil
+}
+
+// coerce converts a raw value to a numeric value, returns
+// false if the value cannot be coerced. Booleans are
+// coerced to 1 and 0.
+func coerce(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// --------------------------------------------------------------------
+
+// CSVReader reads instances from CSV/TSV sources. Numeric attributes are
+// coerced to numbers, nominal attributes are read as strings.
+type CSVReader struct {
+	conf   CSVConfig
+	recs   *csv.Reader
+	closer io.Closer
+
+	attrs   []*core.Attribute // by column, nil if skipped
+	weight  int               // index of the weight column, -1 if none
+	numRecs int
+
+	inst core.MapInstance
+	err  error
+}
+
+// NewCSVReader inits a new reader for a model. If configured,
+// the header row is read immediately.
+func NewCSVReader(r io.Reader, model *core.Model, conf *CSVConfig) (*CSVReader, error) {
+	var c CSVConfig
+	if conf != nil {
+		c = *conf
+	}
+	c.norm()
+
+	recs, names, err := c.open(r)
+	if err != nil {
+		return nil, err
+	}
+	if names == nil {
+		for _, attr := range model.Predictors() {
+			names = append(names, attr.Name)
+		}
+		names = append(names, model.Target().Name)
+		recs.FieldsPerRecord = len(names)
+	}
+
+	s := &CSVReader{
+		conf:   c,
+		recs:   recs,
+		attrs:  make([]*core.Attribute, len(names)),
+		weight: -1,
+	}
+	for i, name := range names {
+		if name == "" {
+			continue
+		} else if name == c.WeightColumn {
+			s.weight = i
+		} else {
+			s.attrs[i] = model.Attribute(c.attributeName(name))
+		}
+	}
+	return s, nil
+}
+
+// OpenCSV opens a CSV/TSV file for reading. See NewCSVReader.
+func OpenCSV(fname string, model *core.Model, conf *CSVConfig) (*CSVReader, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewCSVReader(f, model, conf)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	s.closer = f
+	return s, nil
+}
+
+// Next advances to the next row, returns false when exhausted or on errors
+func (s *CSVReader) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	fields, err := s.recs.Read()
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.numRecs++
+
+	s.inst = make(core.MapInstance, len(s.attrs))
+	for i, str := range fields {
+		if i >= len(s.attrs) {
+			break
+		} else if i == s.weight {
+			if w, ok := coerce(str); ok {
+				s.inst["@weight"] = w
+			} else if !s.conf.isMissing(str) && !s.conf.Lenient {
+				s.err = fmt.Errorf("dataset: record %d: invalid weight %q", s.numRecs, str)
+				return false
+			}
+			continue
+		}
+
+		attr := s.attrs[i]
+		if attr == nil || s.conf.isMissing(str) {
+			continue
+		}
+
+		if attr.IsNominal() {
+			s.inst[attr.Name] = strings.TrimSpace(str)
+		} else if num, ok := coerce(str); ok {
+			s.inst[attr.Name] = num
+		} else if !s.conf.Lenient {
+			s.err = fmt.Errorf("dataset: record %d: invalid numeric value %q for %q", s.numRecs, str, attr.Name)
+			return false
+		}
+	}
+	return true
+}
+
+// ReadN reads up to n instances
+func (s *CSVReader) ReadN(n int) ([]core.Instance, error) {
+	res := make([]core.Instance, 0, n)
+	for s.Next() {
+		res = append(res, s.Instance())
+		if len(res) == n {
+			break
+		}
+	}
+	return res, s.Err()
+}
+
+// Instance returns the current instance
+func (s *CSVReader) Instance() core.Instance { return s.inst }
+
+// Err returns the first error encountered
+func (s *CSVReader) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Close closes the underlying file, if opened via OpenCSV
+func (s *CSVReader) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
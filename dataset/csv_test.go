@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"strings"
+
+	"github.com/bsm/reason/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSVReader", func() {
+	model := core.NewModel(
+		&core.Attribute{Name: "play", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("yes", "no")},
+		&core.Attribute{Name: "outlook", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("sunny", "overcast", "rainy")},
+		&core.Attribute{Name: "temp", Kind: core.AttributeKindNumeric},
+		&core.Attribute{Name: "windy", Kind: core.AttributeKindNumeric},
+	)
+
+	readAll := func(s *CSVReader) []core.Instance {
+		var insts []core.Instance
+		for s.Next() {
+			insts = append(insts, s.Instance())
+		}
+		Expect(s.Err()).NotTo(HaveOccurred())
+		return insts
+	}
+
+	It("should read predictors followed by the target by default", func() {
+		s, err := NewCSVReader(strings.NewReader("sunny,85,false,no\novercast,?,true,yes\n"), model, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "sunny", "temp": 85.0, "windy": 0.0, "play": "no"},
+			core.MapInstance{"outlook": "overcast", "windy": 1.0, "play": "yes"},
+		}))
+	})
+
+	It("should trim values before checking for missing tokens", func() {
+		s, err := NewCSVReader(strings.NewReader("sunny,\"? \",\" \",no\n"), model, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "sunny", "play": "no"},
+		}))
+	})
+
+	It("should map header columns", func() {
+		src := "id\tPlay\tOutlook\tTemperature\tw\n" +
+			"1\tno\tsunny\t85\t2\n" +
+			"2\tyes\tNA\t83\t\n"
+
+		s, err := NewCSVReader(strings.NewReader(src), model, &CSVConfig{
+			Comma:         '\t',
+			Header:        true,
+			Mapping:       map[string]string{"Play": "play", "Outlook": "outlook", "Temperature": "temp"},
+			WeightColumn:  "w",
+			MissingTokens: []string{"", "NA"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		insts := readAll(s)
+		Expect(insts).To(Equal([]core.Instance{
+			core.MapInstance{"play": "no", "outlook": "sunny", "temp": 85.0, "@weight": 2.0},
+			core.MapInstance{"play": "yes", "temp": 83.0},
+		}))
+		Expect(insts[0].GetInstanceWeight()).To(Equal(2.0))
+		Expect(insts[1].GetInstanceWeight()).To(Equal(1.0))
+	})
+
+	It("should select columns", func() {
+		s, err := NewCSVReader(strings.NewReader("a,sunny,85,yes\nb,rainy,70,no\n"), model, &CSVConfig{
+			Columns: []string{"", "outlook", "", "play"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "sunny", "play": "yes"},
+			core.MapInstance{"outlook": "rainy", "play": "no"},
+		}))
+	})
+
+	It("should coerce types", func() {
+		s, err := NewCSVReader(strings.NewReader("sunny,hot,1,no\n"), model, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Next()).To(BeFalse())
+		Expect(s.Err()).To(MatchError(`dataset: record 1: invalid numeric value "hot" for "temp"`))
+
+		s, err = NewCSVReader(strings.NewReader("sunny,hot,1,no\n"), model, &CSVConfig{Lenient: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "sunny", "windy": 1.0, "play": "no"},
+		}))
+	})
+
+	It("should read files", func() {
+		s, err := OpenCSV("../testdata/bigreg.csv", core.NewModel(
+			&core.Attribute{Name: "tv", Kind: core.AttributeKindNumeric},
+			&core.Attribute{Name: "c1", Kind: core.AttributeKindNominal},
+			&core.Attribute{Name: "c2", Kind: core.AttributeKindNominal},
+			&core.Attribute{Name: "c3", Kind: core.AttributeKindNominal},
+			&core.Attribute{Name: "c4", Kind: core.AttributeKindNominal},
+			&core.Attribute{Name: "n1", Kind: core.AttributeKindNumeric},
+		), nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer s.Close()
+
+		insts, err := s.ReadN(2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(insts).To(Equal([]core.Instance{
+			core.MapInstance{"c1": "v1", "c2": "v1", "c3": "v3", "c4": "v8", "n1": 31.0, "tv": 0.8},
+			core.MapInstance{"c1": "v2", "c2": "v2", "c3": "v1", "c4": "v1", "n1": 16.0, "tv": 0.14},
+		}))
+	})
+})
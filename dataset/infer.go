@@ -0,0 +1,124 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/reason/core"
+)
+
+// InferCSVModel scans a sample of up to sampleSize rows (all rows if
+// sampleSize <= 0) of a CSV/TSV source and proposes a model. Columns are
+// numeric if all of their non-missing values in the sample can be parsed
+// as numbers and nominal otherwise, e.g. columns of booleans are nominal.
+// Nominal attributes are initialised with up to 1,000 values in the order
+// of their first appearance, further values are registered as they are
+// read. The target is selected by attribute name or, if blank, is the
+// last column. Column names must be provided by a header row or by the
+// configured Columns. Skipped columns and the weight column are excluded.
+func InferCSVModel(r io.Reader, target string, sampleSize int, conf *CSVConfig) (*core.Model, error) {
+	var c CSVConfig
+	if conf != nil {
+		c = *conf
+	}
+	c.norm()
+
+	recs, names, err := c.open(r)
+	if err != nil {
+		return nil, err
+	}
+	if names == nil {
+		return nil, fmt.Errorf("dataset: unable to infer model without column names")
+	}
+
+	cols := make([]*inferredColumn, len(names))
+	for i, name := range names {
+		if name != "" && name != c.WeightColumn {
+			cols[i] = &inferredColumn{Name: c.attributeName(name), isNumeric: true}
+		}
+	}
+
+	for n := 0; sampleSize <= 0 || n < sampleSize; n++ {
+		fields, err := recs.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for i, str := range fields {
+			if i < len(cols) && cols[i] != nil && !c.isMissing(str) {
+				cols[i].Observe(strings.TrimSpace(str))
+			}
+		}
+	}
+
+	var attrs []*core.Attribute
+	pos := -1
+	for _, col := range cols {
+		if col == nil {
+			continue
+		}
+		if target == "" || col.Name == target {
+			pos = len(attrs)
+		}
+		attrs = append(attrs, col.Attribute())
+	}
+	if pos < 0 {
+		return nil, fmt.Errorf("dataset: target column %q not found", target)
+	}
+	if len(attrs) < 2 {
+		return nil, fmt.Errorf("dataset: at least two columns required, %d found", len(attrs))
+	}
+
+	predictors := make([]*core.Attribute, 0, len(attrs)-1)
+	predictors = append(predictors, attrs[:pos]...)
+	predictors = append(predictors, attrs[pos+1:]...)
+	return core.NewModel(attrs[pos], predictors[0], predictors[1:]...), nil
+}
+
+// maxInferredValues limits the number of distinct values
+// which are collected per column
+const maxInferredValues = 1000
+
+// inferredColumn tracks the values of a column
+type inferredColumn struct {
+	Name string
+
+	isNumeric bool
+	numValues int
+	values    []string
+	seen      map[string]struct{}
+}
+
+// Observe observes a (non-missing) value
+func (c *inferredColumn) Observe(s string) {
+	c.numValues++
+	if c.isNumeric {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			c.isNumeric = false
+		}
+	}
+	if len(c.values) >= maxInferredValues {
+		return
+	}
+
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	if _, ok := c.seen[s]; !ok {
+		c.seen[s] = struct{}{}
+		c.values = append(c.values, s)
+	}
+}
+
+// Attribute returns the proposed attribute. Columns without any
+// values are assumed to be numeric.
+func (c *inferredColumn) Attribute() *core.Attribute {
+	if c.isNumeric || c.numValues == 0 {
+		return &core.Attribute{Name: c.Name, Kind: core.AttributeKindNumeric}
+	}
+	return &core.Attribute{Name: c.Name, Kind: core.AttributeKindNominal, Values: core.NewAttributeValues(c.values...)}
+}
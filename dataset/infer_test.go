@@ -0,0 +1,81 @@
+package dataset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/bsm/reason/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InferCSVModel", func() {
+	const src = `outlook,temp,humidity,windy,w,play
+sunny,85,85,false,1,no
+overcast,83,?,true,2,yes
+rainy,70,high,false,1,yes
+sunny,72,95,true,1,no
+`
+
+	It("should infer models", func() {
+		model, err := InferCSVModel(strings.NewReader(src), "", 0, &CSVConfig{Header: true, WeightColumn: "w"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Target()).To(Equal(&core.Attribute{Name: "play", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("no", "yes")}))
+		Expect(model.Predictors()).To(Equal([]*core.Attribute{
+			{Name: "outlook", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("sunny", "overcast", "rainy")},
+			{Name: "temp", Kind: core.AttributeKindNumeric},
+			{Name: "humidity", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("85", "high", "95")},
+			{Name: "windy", Kind: core.AttributeKindNominal, Values: core.NewAttributeValues("false", "true")},
+		}))
+	})
+
+	It("should limit the number of collected values", func() {
+		buf := new(bytes.Buffer)
+		buf.WriteString("a,b\n")
+		for i := 0; i < 3000; i++ {
+			fmt.Fprintf(buf, "%d,v%d\n", i, i)
+		}
+
+		model, err := InferCSVModel(strings.NewReader(buf.String()), "", 0, &CSVConfig{Header: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Predictor("a").Kind).To(Equal(core.AttributeKindNumeric))
+		Expect(model.Target().Values.Len()).To(Equal(1000))
+		Expect(model.Target().Values.Values()[999]).To(Equal("v999"))
+	})
+
+	It("should trim values before checking for missing tokens", func() {
+		model, err := InferCSVModel(strings.NewReader("a,b\n1,x\n\"? \",y\n"), "", 0, &CSVConfig{Header: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Predictor("a").Kind).To(Equal(core.AttributeKindNumeric))
+	})
+
+	It("should only scan samples", func() {
+		model, err := InferCSVModel(strings.NewReader(src), "temp", 2, &CSVConfig{Header: true, Columns: []string{"outlook", "temp", "humidity"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.IsRegression()).To(BeTrue())
+		Expect(model.NumPredictors()).To(Equal(2))
+		Expect(model.Predictor("humidity").Kind).To(Equal(core.AttributeKindNumeric))
+	})
+
+	It("should fail without column names", func() {
+		_, err := InferCSVModel(strings.NewReader(src), "", 0, nil)
+		Expect(err).To(MatchError(`dataset: unable to infer model without column names`))
+
+		_, err = InferCSVModel(strings.NewReader(src), "unknown", 0, &CSVConfig{Header: true})
+		Expect(err).To(MatchError(`dataset: target column "unknown" not found`))
+	})
+
+	It("should infer models which can read their source", func() {
+		conf := &CSVConfig{Header: true, WeightColumn: "w"}
+		model, err := InferCSVModel(strings.NewReader(src), "play", 0, conf)
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := NewCSVReader(strings.NewReader(src), model, conf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Next()).To(BeTrue())
+		Expect(s.Instance()).To(Equal(core.MapInstance{
+			"outlook": "sunny", "temp": 85.0, "humidity": "85", "windy": "false", "play": "no", "@weight": 1.0,
+		}))
+	})
+})
@@ -0,0 +1,242 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/reason/core"
+)
+
+// JSONLConfig configures JSON Lines readers
+type JSONLConfig struct {
+	// Maps attribute names to field paths. Paths address fields of
+	// nested objects by joining their keys with dots, e.g. "weather.temp".
+	// Default: nil (attribute names are field paths)
+	Paths map[string]string
+
+	// The path of the field containing instance weights.
+	// Default: "@weight"
+	WeightPath string
+
+	// Treat values which cannot be coerced to the kind of their attribute
+	// as missing instead of failing.
+	// Default: false
+	Lenient bool
+}
+
+func (c *JSONLConfig) norm() {
+	if c.WeightPath == "" {
+		c.WeightPath = "@weight"
+	}
+}
+
+// fieldPath returns the field path of an attribute
+func (c *JSONLConfig) fieldPath(name string) string {
+	if path, ok := c.Paths[name]; ok {
+		return path
+	}
+	return name
+}
+
+// jsonPath is a parsed field path
+type jsonPath struct {
+	raw  string
+	keys []string
+}
+
+func newJSONPath(raw string) jsonPath {
+	return jsonPath{raw: raw, keys: strings.Split(raw, ".")}
+}
+
+// Lookup returns the value at the path, nil if not found. Keys which
+// contain the full path take precedence over nested fields.
+func (p jsonPath) Lookup(obj map[string]interface{}) interface{} {
+	if v, ok := obj[p.raw]; ok || len(p.keys) == 1 {
+		return v
+	}
+
+	for _, key := range p.keys[:len(p.keys)-1] {
+		nested, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		obj = nested
+	}
+	return obj[p.keys[len(p.keys)-1]]
+}
+
+// jsonNumber converts a JSON value to a numeric value, returns
+// false if the value cannot be coerced. Booleans are
+// coerced to 1 and 0, strings are parsed.
+func jsonNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		return coerce(n)
+	}
+	return 0, false
+}
+
+// jsonString converts a JSON value to a nominal value, returns
+// false if the value cannot be coerced. Numbers retain their
+// literal representation.
+func jsonString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		return strconv.FormatBool(s), true
+	}
+	return "", false
+}
+
+// --------------------------------------------------------------------
+
+// JSONLReader reads instances from JSON Lines sources, where each record is
+// a JSON object. Numeric attributes are coerced to numbers, nominal
+// attributes are read as strings. Absent fields and null values are missing.
+type JSONLReader struct {
+	conf   JSONLConfig
+	dec    *json.Decoder
+	closer io.Closer
+
+	attrs   []*core.Attribute
+	paths   []jsonPath // by attribute
+	weight  jsonPath
+	numRecs int
+
+	inst core.MapInstance
+	err  error
+}
+
+// NewJSONLReader inits a new reader for a model.
+func NewJSONLReader(r io.Reader, model *core.Model, conf *JSONLConfig) *JSONLReader {
+	var c JSONLConfig
+	if conf != nil {
+		c = *conf
+	}
+	c.norm()
+
+	attrs := append([]*core.Attribute{model.Target()}, model.Predictors()...)
+	paths := make([]jsonPath, len(attrs))
+	for i, attr := range attrs {
+		paths[i] = newJSONPath(c.fieldPath(attr.Name))
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	return &JSONLReader{
+		conf:   c,
+		dec:    dec,
+		attrs:  attrs,
+		paths:  paths,
+		weight: newJSONPath(c.WeightPath),
+	}
+}
+
+// OpenJSONL opens a JSON Lines file for reading. See NewJSONLReader.
+func OpenJSONL(fname string, model *core.Model, conf *JSONLConfig) (*JSONLReader, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewJSONLReader(f, model, conf)
+	s.closer = f
+	return s, nil
+}
+
+// Next advances to the next record, returns false when exhausted or on errors
+func (s *JSONLReader) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	var obj map[string]interface{}
+	if err := s.dec.Decode(&obj); err == io.EOF {
+		s.err = err
+		return false
+	} else if err != nil {
+		s.err = fmt.Errorf("dataset: record %d: %v", s.numRecs+1, err)
+		return false
+	}
+	s.numRecs++
+
+	s.inst = make(core.MapInstance, len(s.attrs))
+	if v := s.weight.Lookup(obj); v != nil {
+		if w, ok := jsonNumber(v); ok {
+			s.inst["@weight"] = w
+		} else if !s.conf.Lenient {
+			s.err = fmt.Errorf("dataset: record %d: invalid weight %v", s.numRecs, v)
+			return false
+		}
+	}
+
+	for i, attr := range s.attrs {
+		v := s.paths[i].Lookup(obj)
+		if v == nil {
+			continue
+		}
+
+		if attr.IsNominal() {
+			if str, ok := jsonString(v); ok {
+				s.inst[attr.Name] = str
+				continue
+			}
+		} else if num, ok := jsonNumber(v); ok {
+			s.inst[attr.Name] = num
+			continue
+		}
+
+		if !s.conf.Lenient {
+			s.err = fmt.Errorf("dataset: record %d: invalid %s value %v for %q", s.numRecs, attr.Kind, v, attr.Name)
+			return false
+		}
+	}
+	return true
+}
+
+// ReadN reads up to n instances
+func (s *JSONLReader) ReadN(n int) ([]core.Instance, error) {
+	res := make([]core.Instance, 0, n)
+	for s.Next() {
+		res = append(res, s.Instance())
+		if len(res) == n {
+			break
+		}
+	}
+	return res, s.Err()
+}
+
+// Instance returns the current instance
+func (s *JSONLReader) Instance() core.Instance { return s.inst }
+
+// Err returns the first error encountered
+func (s *JSONLReader) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Close closes the underlying file, if opened via OpenJSONL
+func (s *JSONLReader) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package dataset
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/bsm/reason/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONLReader", func() {
+	const schema = `{
+		"target": {"name": "play", "kind": "nominal", "values": ["yes", "no"]},
+		"predictors": [
+			{"name": "outlook", "kind": "nominal", "values": ["sunny", "overcast", "rainy"]},
+			{"name": "temp", "kind": "numeric"},
+			{"name": "windy", "kind": "numeric"}
+		]
+	}`
+
+	var model *core.Model
+
+	BeforeEach(func() {
+		model = new(core.Model)
+		Expect(json.Unmarshal([]byte(schema), model)).To(Succeed())
+	})
+
+	readAll := func(s *JSONLReader) []core.Instance {
+		var insts []core.Instance
+		for s.Next() {
+			insts = append(insts, s.Instance())
+		}
+		Expect(s.Err()).NotTo(HaveOccurred())
+		return insts
+	}
+
+	It("should read records", func() {
+		s := NewJSONLReader(strings.NewReader(`{"outlook":"sunny","temp":85,"windy":false,"play":"no","id":1}
+{"outlook":null,"temp":"83","windy":true,"play":"yes","@weight":2}
+
+{"temp":70}
+`), model, nil)
+
+		insts := readAll(s)
+		Expect(insts).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "sunny", "temp": 85.0, "windy": 0.0, "play": "no"},
+			core.MapInstance{"temp": 83.0, "windy": 1.0, "play": "yes", "@weight": 2.0},
+			core.MapInstance{"temp": 70.0},
+		}))
+		Expect(insts[1].GetInstanceWeight()).To(Equal(2.0))
+		Expect(model.Predictor("outlook").Value(insts[1]).IsMissing()).To(BeTrue())
+	})
+
+	It("should read nested field paths", func() {
+		s := NewJSONLReader(strings.NewReader(`{"event":{"weather":{"outlook":"rainy","temp":70.5,"windy":1}},"label":"yes","meta":{"w":0.5}}
+{"event":{"weather":null},"event.label":"no","label":"yes"}
+`), model, &JSONLConfig{
+			Paths:      map[string]string{"play": "event.label", "outlook": "event.weather.outlook", "temp": "event.weather.temp", "windy": "event.weather.windy"},
+			WeightPath: "meta.w",
+		})
+
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "rainy", "temp": 70.5, "windy": 1.0, "@weight": 0.5},
+			core.MapInstance{"play": "no"},
+		}))
+	})
+
+	It("should coerce types", func() {
+		s := NewJSONLReader(strings.NewReader(`{"outlook":1,"temp":"hot","play":true}`), model, nil)
+		Expect(s.Next()).To(BeFalse())
+		Expect(s.Err()).To(MatchError(`dataset: record 1: invalid numeric value hot for "temp"`))
+
+		s = NewJSONLReader(strings.NewReader(`{"outlook":1,"temp":"hot","windy":[1],"play":true}`), model, &JSONLConfig{Lenient: true})
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"outlook": "1", "play": "true"},
+		}))
+	})
+
+	It("should retain large integers", func() {
+		model := core.NewModel(
+			&core.Attribute{Name: "id", Kind: core.AttributeKindNominal},
+			&core.Attribute{Name: "num", Kind: core.AttributeKindNumeric},
+		)
+		s := NewJSONLReader(strings.NewReader(`{"id":9007199254740993,"num":9007199254740993}`), model, nil)
+		Expect(readAll(s)).To(Equal([]core.Instance{
+			core.MapInstance{"id": "9007199254740993", "num": 9007199254740992.0},
+		}))
+	})
+
+	It("should fail on invalid records", func() {
+		s := NewJSONLReader(strings.NewReader("{\"temp\":1}\n[1,2]\n"), model, nil)
+		Expect(s.Next()).To(BeTrue())
+		Expect(s.Next()).To(BeFalse())
+		Expect(s.Err()).To(MatchError(`dataset: record 2: json: cannot unmarshal array into Go value of type map[string]interface {}`))
+	})
+
+	It("should read in batches", func() {
+		s := NewJSONLReader(strings.NewReader(`{"temp":1} {"temp":2} {"temp":3}`), model, nil)
+		insts, err := s.ReadN(2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(insts).To(HaveLen(2))
+
+		insts, err = s.ReadN(2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(insts).To(Equal([]core.Instance{core.MapInstance{"temp": 3.0}}))
+		Expect(s.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,189 @@
+package drift
+
+import (
+	"math"
+
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7751, (*ADWIN)(nil))
+}
+
+const (
+	adwinMaxBuckets = 5
+	adwinMinWidth   = 5
+	adwinClock      = 32
+)
+
+// ADWIN is an adaptive sliding window, which detects changes in the mean of
+// a stream of values. See "Learning from Time-Changing Data with Adaptive
+// Windowing" by Albert Bifet and Ricard Gavaldà (2007).
+type ADWIN struct {
+	delta float64
+
+	// sums and vars hold the exponential histogram, row i contains
+	// buckets of size 2^i, oldest first
+	sums, vars [][]float64
+
+	width    int
+	total    float64
+	variance float64
+	ticks    int
+
+	detected bool
+}
+
+// NewADWIN inits a new ADWIN with a given confidence value.
+// Default: 0.002
+func NewADWIN(delta float64) *ADWIN {
+	if delta <= 0 {
+		delta = 0.002
+	}
+	return &ADWIN{delta: delta}
+}
+
+// Width returns the number of values in the current window
+func (a *ADWIN) Width() int { return a.width }
+
+// Estimate returns the mean of the values in the current window
+func (a *ADWIN) Estimate() float64 {
+	if a.width == 0 {
+		return 0.0
+	}
+	return a.total / float64(a.width)
+}
+
+// Detected returns true if a change was detected when
+// the last value was added
+func (a *ADWIN) Detected() bool { return a.detected }
+
+// Warning always returns false, ADWIN has no warning zone
+func (a *ADWIN) Warning() bool { return false }
+
+// Add adds a value to the window and checks for changes.
+// Older values are dropped from the window once a change
+// is detected.
+func (a *ADWIN) Add(v float64) {
+	a.insert(v)
+
+	if a.ticks++; a.ticks%adwinClock != 0 || a.width <= adwinMinWidth {
+		a.detected = false
+		return
+	}
+	a.detected = a.shrink()
+}
+
+func (a *ADWIN) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(a.delta, a.sums, a.vars, a.width, a.total, a.variance, a.ticks, a.detected)
+}
+
+func (a *ADWIN) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&a.delta, &a.sums, &a.vars, &a.width, &a.total, &a.variance, &a.ticks, &a.detected)
+}
+
+func (a *ADWIN) insert(v float64) {
+	if a.width++; a.width > 1 {
+		n := float64(a.width)
+		d := v - a.total/(n-1)
+		a.variance += (n - 1) * d * d / n
+	}
+	a.total += v
+
+	if len(a.sums) == 0 {
+		a.sums = [][]float64{{}}
+		a.vars = [][]float64{{}}
+	}
+	a.sums[0] = append(a.sums[0], v)
+	a.vars[0] = append(a.vars[0], 0)
+	a.compress()
+}
+
+// compress merges the two oldest buckets of each row that exceeds
+// the maximum number of buckets
+func (a *ADWIN) compress() {
+	for i := 0; i < len(a.sums) && len(a.sums[i]) > adwinMaxBuckets; i++ {
+		if i+1 == len(a.sums) {
+			a.sums = append(a.sums, []float64{})
+			a.vars = append(a.vars, []float64{})
+		}
+
+		n := float64(int(1) << uint(i))
+		s0, s1 := a.sums[i][0], a.sums[i][1]
+		d := s0/n - s1/n
+
+		a.sums[i+1] = append(a.sums[i+1], s0+s1)
+		a.vars[i+1] = append(a.vars[i+1], a.vars[i][0]+a.vars[i][1]+n*d*d/2)
+		a.sums[i] = append(a.sums[i][:0], a.sums[i][2:]...)
+		a.vars[i] = append(a.vars[i][:0], a.vars[i][2:]...)
+	}
+}
+
+// shrink drops the oldest buckets for as long as two sub-windows
+// exhibit distinct enough means
+func (a *ADWIN) shrink() bool {
+	changed := false
+	for reduce := true; reduce; {
+		reduce = false
+
+		n0, n1 := 0.0, float64(a.width)
+		u0, u1 := 0.0, a.total
+
+	scan:
+		for i := len(a.sums) - 1; i > -1; i-- {
+			size := float64(int(1) << uint(i))
+			for k, s := range a.sums[i] {
+				n0 += size
+				n1 -= size
+				u0 += s
+				u1 -= s
+
+				if i == 0 && k == len(a.sums[i])-1 {
+					break scan
+				}
+
+				if n0 > adwinMinWidth+1 && n1 > adwinMinWidth+1 && a.isCut(n0, n1, u0/n0-u1/n1) {
+					changed, reduce = true, true
+					a.dropOldest()
+					break scan
+				}
+			}
+		}
+	}
+	return changed
+}
+
+func (a *ADWIN) isCut(n0, n1, diff float64) bool {
+	n := float64(a.width)
+	dd := math.Log(2 * math.Log(n) / a.delta)
+	v := a.variance / n
+	m := 1/(n0-adwinMinWidth+1) + 1/(n1-adwinMinWidth+1)
+	eps := math.Sqrt(2*m*v*dd) + 2.0/3.0*dd*m
+	return math.Abs(diff) > eps
+}
+
+func (a *ADWIN) dropOldest() {
+	i := len(a.sums) - 1
+	if i < 0 {
+		return
+	}
+
+	size := float64(int(1) << uint(i))
+	sum, vari := a.sums[i][0], a.vars[i][0]
+
+	a.width -= int(size)
+	a.total -= sum
+	if n := float64(a.width); n > 0 {
+		d := sum/size - a.total/n
+		a.variance -= vari + size*n*d*d/(size+n)
+	} else {
+		a.variance = 0
+	}
+
+	if a.sums[i] = a.sums[i][1:]; len(a.sums[i]) == 0 {
+		a.sums = a.sums[:i]
+		a.vars = a.vars[:i]
+	} else {
+		a.vars[i] = a.vars[i][1:]
+	}
+}
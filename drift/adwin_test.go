@@ -0,0 +1,71 @@
+package drift
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/bsm/reason/internal/msgpack"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ADWIN", func() {
+	var subject *ADWIN
+
+	BeforeEach(func() {
+		subject = NewADWIN(0)
+	})
+
+	It("should estimate", func() {
+		for i := 0; i < 100; i++ {
+			subject.Add(float64(i % 2))
+		}
+		Expect(subject.Width()).To(Equal(100))
+		Expect(subject.Estimate()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.sums).To(HaveLen(5))
+	})
+
+	It("should not detect changes in stationary streams", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			subject.Add(float64(rnd.Intn(5) / 4))
+			Expect(subject.Detected()).To(BeFalse())
+			Expect(subject.Warning()).To(BeFalse())
+		}
+		Expect(subject.Width()).To(Equal(5000))
+	})
+
+	It("should detect changes", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			subject.Add(rnd.Float64() * 0.4)
+		}
+		Expect(subject.Estimate()).To(BeNumerically("~", 0.2, 0.02))
+
+		detected := 0
+		for i := 0; i < 1000; i++ {
+			if subject.Add(0.6 + rnd.Float64()*0.4); subject.Detected() {
+				detected++
+			}
+		}
+		Expect(detected).To(BeNumerically(">", 0))
+		Expect(subject.Width()).To(BeNumerically("<", 1100))
+		Expect(subject.Estimate()).To(BeNumerically("~", 0.8, 0.05))
+	})
+
+	It("should encode/decode", func() {
+		for i := 0; i < 100; i++ {
+			subject.Add(float64(i % 3))
+		}
+
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		Expect(enc.Encode(subject)).To(Succeed())
+		Expect(enc.Close()).To(Succeed())
+
+		var out *ADWIN
+		Expect(msgpack.NewDecoder(buf).Decode(&out)).To(Succeed())
+		Expect(out).To(Equal(subject))
+	})
+
+})
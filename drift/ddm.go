@@ -0,0 +1,96 @@
+package drift
+
+import (
+	"math"
+
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7755, (*DDM)(nil))
+}
+
+const ddmMinCount = 30
+
+// DDM is the drift detection method, which monitors the error rate of a
+// stream of binary (0 or 1) prediction errors. See "Learning with Drift
+// Detection" by João Gama et al. (2004).
+type DDM struct {
+	warningLevel, driftLevel float64
+
+	count, rate float64
+	minRate     float64
+	minStdDev   float64
+
+	warning, detected bool
+}
+
+// NewDDM inits a new detector with a given warning level (default: 2)
+// and drift level (default: 3), expressed in standard deviations.
+func NewDDM(warningLevel, driftLevel float64) *DDM {
+	if warningLevel <= 0 {
+		warningLevel = 2
+	}
+	if driftLevel <= 0 {
+		driftLevel = 3
+	}
+	d := &DDM{warningLevel: warningLevel, driftLevel: driftLevel}
+	d.Reset()
+	return d
+}
+
+// Count returns the number of values observed since the last reset
+func (d *DDM) Count() float64 { return d.count }
+
+// Estimate returns the error rate observed since the last reset
+func (d *DDM) Estimate() float64 { return d.rate }
+
+// Detected returns true if a drift was detected when
+// the last value was added
+func (d *DDM) Detected() bool { return d.detected }
+
+// Warning returns true if the detector is in the warning zone
+func (d *DDM) Warning() bool { return d.warning }
+
+// Add adds a prediction error. The detector resets after
+// each detection.
+func (d *DDM) Add(x float64) {
+	d.detected, d.warning = false, false
+	if math.IsNaN(x) {
+		return
+	}
+
+	d.count++
+	d.rate += (x - d.rate) / d.count
+	if d.count < ddmMinCount {
+		return
+	}
+
+	stdDev := math.Sqrt(d.rate * (1 - d.rate) / d.count)
+	if d.rate+stdDev <= d.minRate+d.minStdDev {
+		d.minRate, d.minStdDev = d.rate, stdDev
+	}
+
+	switch level := d.rate + stdDev; {
+	case level > d.minRate+d.driftLevel*d.minStdDev:
+		d.Reset()
+		d.detected = true
+	case level > d.minRate+d.warningLevel*d.minStdDev:
+		d.warning = true
+	}
+}
+
+// Reset resets the detector
+func (d *DDM) Reset() {
+	d.count, d.rate = 0, 0
+	d.minRate, d.minStdDev = math.MaxFloat64, math.MaxFloat64
+	d.warning, d.detected = false, false
+}
+
+func (d *DDM) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(d.warningLevel, d.driftLevel, d.count, d.rate, d.minRate, d.minStdDev, d.warning, d.detected)
+}
+
+func (d *DDM) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&d.warningLevel, &d.driftLevel, &d.count, &d.rate, &d.minRate, &d.minStdDev, &d.warning, &d.detected)
+}
@@ -0,0 +1,23 @@
+// Package drift implements concept drift detectors, which monitor
+// streams of values, such as prediction errors, for changes.
+package drift
+
+// Detector implementations detect changes in streams of values
+type Detector interface {
+	// Add adds a value
+	Add(x float64)
+	// Detected returns true if a drift was detected when the last value was added
+	Detected() bool
+	// Warning returns true if the detector is in a warning zone
+	Warning() bool
+	// Estimate returns the current estimate of the mean value
+	Estimate() float64
+}
+
+var (
+	_ Detector = (*ADWIN)(nil)
+	_ Detector = (*PageHinkley)(nil)
+	_ Detector = (*DDM)(nil)
+	_ Detector = (*EDDM)(nil)
+	_ Detector = (*HDDM)(nil)
+)
@@ -0,0 +1,138 @@
+package drift
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/reason/internal/msgpack"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detector", func() {
+
+	// errors generates binary errors at a given rate
+	errors := func(rnd *rand.Rand, rate float64) float64 {
+		if rnd.Float64() < rate {
+			return 1
+		}
+		return 0
+	}
+
+	DescribeTable("should not detect changes in stationary streams",
+		func(subject Detector) {
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 200; i++ {
+				subject.Add(errors(rnd, 0.2))
+			}
+			for i := 0; i < 5000; i++ {
+				subject.Add(errors(rnd, 0.2))
+				Expect(subject.Detected()).To(BeFalse(), "at %d", i)
+			}
+			Expect(subject.Estimate()).To(BeNumerically("~", 0.2, 0.02))
+		},
+
+		Entry("ADWIN", NewADWIN(0)),
+		Entry("DDM", NewDDM(0, 0)),
+		Entry("HDDM", NewHDDM(0, 0)),
+		Entry("Page-Hinkley", NewPageHinkley(0, 0)),
+	)
+
+	DescribeTable("should detect increasing error rates",
+		func(subject Detector, maxDelay int) {
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 2000; i++ {
+				subject.Add(errors(rnd, 0.1))
+			}
+
+			pos, warn := -1, -1
+			for i := 0; i < 2000 && pos < 0; i++ {
+				if subject.Add(errors(rnd, 0.6)); subject.Detected() {
+					pos = i
+				} else if warn < 0 && subject.Warning() {
+					warn = i
+				}
+			}
+			Expect(pos).To(BeNumerically(">", 0))
+			Expect(pos).To(BeNumerically("<", maxDelay))
+			Expect(warn).To(BeNumerically("<", pos))
+		},
+
+		Entry("ADWIN", NewADWIN(0), 100),
+		Entry("DDM", NewDDM(0, 0), 500),
+		Entry("EDDM", NewEDDM(0, 0), 200),
+		Entry("HDDM", NewHDDM(0, 0), 100),
+	)
+
+	DescribeTable("should encode/decode",
+		func(subject Detector) {
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				subject.Add(errors(rnd, 0.3))
+			}
+
+			buf := new(bytes.Buffer)
+			enc := msgpack.NewEncoder(buf)
+			Expect(enc.Encode(subject)).To(Succeed())
+			Expect(enc.Close()).To(Succeed())
+
+			var out Detector
+			Expect(msgpack.NewDecoder(buf).Decode(&out)).To(Succeed())
+			Expect(out).To(Equal(subject))
+		},
+
+		Entry("DDM", NewDDM(0, 0)),
+		Entry("EDDM", NewEDDM(0, 0)),
+		Entry("HDDM", NewHDDM(0, 0)),
+	)
+
+	DescribeTable("should persist detection states",
+		func(subject Detector) {
+			roundTrip := func() Detector {
+				buf := new(bytes.Buffer)
+				enc := msgpack.NewEncoder(buf)
+				Expect(enc.Encode(subject)).To(Succeed())
+				Expect(enc.Close()).To(Succeed())
+
+				var out Detector
+				Expect(msgpack.NewDecoder(buf).Decode(&out)).To(Succeed())
+				return out
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 1000; i++ {
+				subject.Add(errors(rnd, 0.1))
+			}
+
+			detected, warned := false, false
+			for i := 0; i < 2000 && !detected; i++ {
+				subject.Add(errors(rnd, 0.6))
+				detected, warned = subject.Detected(), warned || subject.Warning()
+
+				out := roundTrip()
+				Expect(out.Detected()).To(Equal(subject.Detected()), "at %d", i)
+				Expect(out.Warning()).To(Equal(subject.Warning()), "at %d", i)
+			}
+			Expect(detected).To(BeTrue())
+			if _, ok := subject.(*ADWIN); !ok {
+				Expect(warned).To(BeTrue())
+			}
+		},
+
+		Entry("ADWIN", NewADWIN(0)),
+		Entry("DDM", NewDDM(0, 0)),
+		Entry("EDDM", NewEDDM(0, 0)),
+		Entry("HDDM", NewHDDM(0, 0)),
+		Entry("Page-Hinkley", NewPageHinkley(0, 0)),
+	)
+
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "drift")
+}
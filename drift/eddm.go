@@ -0,0 +1,114 @@
+package drift
+
+import (
+	"math"
+
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7756, (*EDDM)(nil))
+}
+
+const eddmMinErrors = 30
+
+// EDDM is the early drift detection method, which monitors the distance
+// between errors in a stream of binary (0 or 1) prediction errors. It is
+// more suitable than DDM for detecting gradual drifts. See "Early Drift
+// Detection Method" by Manuel Baena-García et al. (2006).
+type EDDM struct {
+	warningLevel, driftLevel float64
+
+	count, errors float64
+	lastError     float64
+
+	// running mean and sum of squared deviations of the distances
+	mean, m2 float64
+	max      float64
+
+	warning, detected bool
+}
+
+// NewEDDM inits a new detector with a given warning level (default: 0.95)
+// and drift level (default: 0.9), expressed as ratios to the maximum
+// observed distance.
+func NewEDDM(warningLevel, driftLevel float64) *EDDM {
+	if warningLevel <= 0 {
+		warningLevel = 0.95
+	}
+	if driftLevel <= 0 {
+		driftLevel = 0.9
+	}
+	return &EDDM{warningLevel: warningLevel, driftLevel: driftLevel}
+}
+
+// Estimate returns the error rate observed since the last reset
+func (d *EDDM) Estimate() float64 {
+	if d.count == 0 {
+		return 0.0
+	}
+	return d.errors / d.count
+}
+
+// Distance returns the mean distance between errors
+func (d *EDDM) Distance() float64 { return d.mean }
+
+// Detected returns true if a drift was detected when
+// the last value was added
+func (d *EDDM) Detected() bool { return d.detected }
+
+// Warning returns true if the detector is in the warning zone
+func (d *EDDM) Warning() bool { return d.warning }
+
+// Add adds a prediction error, values >= 0.5 are considered
+// errors. The detector resets after each detection.
+func (d *EDDM) Add(x float64) {
+	d.detected, d.warning = false, false
+	if math.IsNaN(x) {
+		return
+	}
+
+	if d.count++; x < 0.5 {
+		return
+	}
+
+	d.errors++
+	dist := d.count - d.lastError
+	d.lastError = d.count
+
+	delta := dist - d.mean
+	d.mean += delta / d.errors
+	d.m2 += delta * (dist - d.mean)
+	if d.errors < eddmMinErrors {
+		return
+	}
+
+	level := d.mean + 2*math.Sqrt(d.m2/d.errors)
+	if level > d.max {
+		d.max = level
+		return
+	}
+
+	switch ratio := level / d.max; {
+	case ratio < d.driftLevel:
+		d.Reset()
+		d.detected = true
+	case ratio < d.warningLevel:
+		d.warning = true
+	}
+}
+
+// Reset resets the detector
+func (d *EDDM) Reset() {
+	d.count, d.errors, d.lastError = 0, 0, 0
+	d.mean, d.m2, d.max = 0, 0, 0
+	d.warning, d.detected = false, false
+}
+
+func (d *EDDM) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(d.warningLevel, d.driftLevel, d.count, d.errors, d.lastError, d.mean, d.m2, d.max, d.warning, d.detected)
+}
+
+func (d *EDDM) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&d.warningLevel, &d.driftLevel, &d.count, &d.errors, &d.lastError, &d.mean, &d.m2, &d.max, &d.warning, &d.detected)
+}
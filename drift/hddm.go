@@ -0,0 +1,113 @@
+package drift
+
+import (
+	"math"
+
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7757, (*HDDM)(nil))
+}
+
+// HDDM is a drift detection method based on Hoeffding's bounds, which
+// monitors the mean of a stream of values in the range of [0, 1], such as
+// prediction errors, for increases. This is the A-test variant, which
+// compares moving averages. See "Online and Non-Parametric Drift Detection
+// Methods Based on Hoeffding's Bounds" by Isvani Frías-Blanco et al. (2015).
+type HDDM struct {
+	warningConfidence, driftConfidence float64
+
+	count, sum       float64
+	cutCount, cutSum float64
+
+	warning, detected bool
+}
+
+// NewHDDM inits a new detector with a given warning confidence
+// (default: 0.005) and drift confidence (default: 0.001).
+func NewHDDM(warningConfidence, driftConfidence float64) *HDDM {
+	if warningConfidence <= 0 {
+		warningConfidence = 0.005
+	}
+	if driftConfidence <= 0 {
+		driftConfidence = 0.001
+	}
+	return &HDDM{warningConfidence: warningConfidence, driftConfidence: driftConfidence}
+}
+
+// Count returns the number of values observed since the last reset
+func (d *HDDM) Count() float64 { return d.count }
+
+// Estimate returns the mean of the values observed since the last reset
+func (d *HDDM) Estimate() float64 {
+	if d.count == 0 {
+		return 0.0
+	}
+	return d.sum / d.count
+}
+
+// Detected returns true if a drift was detected when
+// the last value was added
+func (d *HDDM) Detected() bool { return d.detected }
+
+// Warning returns true if the detector is in the warning zone
+func (d *HDDM) Warning() bool { return d.warning }
+
+// Add adds a value and tests for an increase of the mean.
+// The detector resets after each detection.
+func (d *HDDM) Add(x float64) {
+	d.detected, d.warning = false, false
+	if math.IsNaN(x) {
+		return
+	}
+
+	d.count++
+	d.sum += x
+
+	// Move the cut point if the upper bound of the current mean
+	// is lower than the upper bound of the mean at the cut point
+	if d.cutCount == 0 || d.sum/d.count+d.bound(d.count) <= d.cutSum/d.cutCount+d.bound(d.cutCount) {
+		d.cutCount, d.cutSum = d.count, d.sum
+	}
+
+	switch {
+	case d.isIncrease(d.driftConfidence):
+		d.Reset()
+		d.detected = true
+	case d.isIncrease(d.warningConfidence):
+		d.warning = true
+	}
+}
+
+// Reset resets the detector
+func (d *HDDM) Reset() {
+	d.count, d.sum = 0, 0
+	d.cutCount, d.cutSum = 0, 0
+	d.warning, d.detected = false, false
+}
+
+func (d *HDDM) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(d.warningConfidence, d.driftConfidence, d.count, d.sum, d.cutCount, d.cutSum, d.warning, d.detected)
+}
+
+func (d *HDDM) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&d.warningConfidence, &d.driftConfidence, &d.count, &d.sum, &d.cutCount, &d.cutSum, &d.warning, &d.detected)
+}
+
+// bound returns the Hoeffding bound for n values at drift confidence
+func (d *HDDM) bound(n float64) float64 {
+	return math.Sqrt(math.Log(1/d.driftConfidence) / (2 * n))
+}
+
+// isIncrease tests whether the mean of all values exceeds
+// the mean at the cut point at a given confidence
+func (d *HDDM) isIncrease(confidence float64) bool {
+	if d.cutCount == d.count {
+		return false
+	}
+
+	m := (d.count - d.cutCount) / (d.cutCount * d.count)
+	eps := math.Sqrt(m / 2 * math.Log(2/confidence))
+	return d.sum/d.count-d.cutSum/d.cutCount >= eps
+}
@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"math"
+
+	"github.com/bsm/reason/internal/msgpack"
+)
+
+func init() {
+	msgpack.Register(7752, (*PageHinkley)(nil))
+}
+
+const pageHinkleyMinCount = 30
+
+// PageHinkley implements the Page-Hinkley test, which detects increases
+// in the mean of a stream of values. See "Continuous Inspection Schemes"
+// by E. S. Page (1954).
+type PageHinkley struct {
+	delta, lambda float64
+
+	count float64
+	mean  float64
+	sum   float64
+	min   float64
+
+	detected bool
+}
+
+// NewPageHinkley inits a new test with a given magnitude of tolerated
+// changes (default: 0.005) and a detection threshold (default: 50).
+func NewPageHinkley(delta, lambda float64) *PageHinkley {
+	if delta <= 0 {
+		delta = 0.005
+	}
+	if lambda <= 0 {
+		lambda = 50
+	}
+	return &PageHinkley{delta: delta, lambda: lambda}
+}
+
+// Count returns the number of values observed since the last reset
+func (p *PageHinkley) Count() float64 { return p.count }
+
+// Estimate returns the mean of the values observed since the last reset
+func (p *PageHinkley) Estimate() float64 { return p.mean }
+
+// Detected returns true if an increase of the mean was
+// detected when the last value was added
+func (p *PageHinkley) Detected() bool { return p.detected }
+
+// Warning returns true if the test statistic exceeds
+// half of the detection threshold
+func (p *PageHinkley) Warning() bool {
+	return p.count >= pageHinkleyMinCount && p.sum-p.min > p.lambda/2
+}
+
+// Add adds a value and tests for an increase of the mean.
+// The test resets after each detection.
+func (p *PageHinkley) Add(v float64) {
+	p.detected = false
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+
+	p.count++
+	p.mean += (v - p.mean) / p.count
+	p.sum += v - p.mean - p.delta
+	if p.sum < p.min {
+		p.min = p.sum
+	}
+
+	if p.count >= pageHinkleyMinCount && p.sum-p.min > p.lambda {
+		p.Reset()
+		p.detected = true
+	}
+}
+
+// Reset resets the test
+func (p *PageHinkley) Reset() {
+	p.count, p.mean, p.sum, p.min = 0, 0, 0, 0
+	p.detected = false
+}
+
+func (p *PageHinkley) EncodeTo(enc *msgpack.Encoder) error {
+	return enc.Encode(p.delta, p.lambda, p.count, p.mean, p.sum, p.min, p.detected)
+}
+
+func (p *PageHinkley) DecodeFrom(dec *msgpack.Decoder) error {
+	return dec.Decode(&p.delta, &p.lambda, &p.count, &p.mean, &p.sum, &p.min, &p.detected)
+}
@@ -0,0 +1,72 @@
+package drift
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/bsm/reason/internal/msgpack"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageHinkley", func() {
+	var subject *PageHinkley
+
+	BeforeEach(func() {
+		subject = NewPageHinkley(0, 0)
+	})
+
+	It("should estimate", func() {
+		for i := 0; i < 100; i++ {
+			subject.Add(float64(i % 2))
+		}
+		Expect(subject.Count()).To(Equal(100.0))
+		Expect(subject.Estimate()).To(BeNumerically("~", 0.5, 0.001))
+	})
+
+	It("should not detect changes in stationary streams", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			subject.Add(rnd.Float64())
+			Expect(subject.Detected()).To(BeFalse())
+		}
+	})
+
+	It("should detect increases", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			subject.Add(rnd.Float64())
+			Expect(subject.Detected()).To(BeFalse())
+		}
+
+		pos, warn := -1, -1
+		for i := 0; i < 1000; i++ {
+			if subject.Add(2 + rnd.Float64()); subject.Detected() {
+				pos = i
+				break
+			} else if warn < 0 && subject.Warning() {
+				warn = i
+			}
+		}
+		Expect(warn).To(BeNumerically(">=", 0))
+		Expect(warn).To(BeNumerically("<", pos))
+		Expect(pos).To(BeNumerically("~", 30, 10))
+		Expect(subject.Count()).To(Equal(0.0))
+	})
+
+	It("should encode/decode", func() {
+		for i := 0; i < 100; i++ {
+			subject.Add(float64(i % 3))
+		}
+
+		buf := new(bytes.Buffer)
+		enc := msgpack.NewEncoder(buf)
+		Expect(enc.Encode(subject)).To(Succeed())
+		Expect(enc.Close()).To(Succeed())
+
+		var out *PageHinkley
+		Expect(msgpack.NewDecoder(buf).Decode(&out)).To(Succeed())
+		Expect(out).To(Equal(subject))
+	})
+
+})
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
 	"github.com/bsm/reason/internal/stats"
 )
 
@@ -14,14 +15,22 @@ type Classification struct {
 
 	weight, correct float64
 
+	// track the performance of a no-change classifier,
+	// which always predicts the previous actual class
+	lastActual int
+	noChange   float64
+
+	detector drift.Detector
+
 	mu sync.Mutex
 }
 
 // NewClassification inits a new evaluator
 func NewClassification(model *core.Model) *Classification {
 	return &Classification{
-		model: model,
-		kappa: stats.NewKappa(),
+		model:      model,
+		kappa:      stats.NewKappa(),
+		lastActual: -1,
 	}
 }
 
@@ -37,15 +46,27 @@ func (e *Classification) Record(inst core.Instance, prediction core.Prediction)
 		return
 	}
 
-	ai := av.Index()
-	weight := inst.GetInstanceWeight()
+	e.record(pi, av.Index(), inst.GetInstanceWeight())
+}
 
+func (e *Classification) record(pi, ai int, weight float64) {
 	e.mu.Lock()
 	e.weight += weight
 	if pi == ai {
 		e.correct += weight
 	}
+	if ai == e.lastActual {
+		e.noChange += weight
+	}
+	e.lastActual = ai
 	e.kappa.Record(pi, ai, weight)
+	if e.detector != nil {
+		if pi == ai {
+			e.detector.Add(0)
+		} else {
+			e.detector.Add(1)
+		}
+	}
 	e.mu.Unlock()
 }
 
@@ -76,3 +97,101 @@ func (e *Classification) Kappa() float64 {
 	e.mu.Unlock()
 	return kappa
 }
+
+// SetDetector sets a drift detector which is fed with the 0/1 loss
+// of each recorded prediction
+func (e *Classification) SetDetector(d drift.Detector) {
+	e.mu.Lock()
+	e.detector = d
+	e.mu.Unlock()
+}
+
+// Drift returns true if a drift of the error rate was detected when the
+// last prediction was recorded and/or if the detector is in a warning
+// zone. Always returns false if no detector is set.
+func (e *Classification) Drift() (detected, warning bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.detector == nil {
+		return false, false
+	}
+	return e.detector.Detected(), e.detector.Warning()
+}
+
+// KappaM returns the kappa-M statistic, which compares the accuracy with
+// the accuracy of a majority-class classifier. It is more suitable than
+// Cohen's kappa for streams with imbalanced classes.
+func (e *Classification) KappaM() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.weight == 0 {
+		return 0.0
+	}
+
+	majority := 0.0
+	for _, row := range e.kappa.Matrix() {
+		sum := 0.0
+		for _, w := range row {
+			sum += w
+		}
+		if sum > majority {
+			majority = sum
+		}
+	}
+	return relativeImprovement(e.correct/e.weight, majority/e.weight)
+}
+
+// KappaT returns the kappa-temporal statistic, which compares the accuracy
+// with the accuracy of a no-change classifier, always predicting the
+// previously observed class. It is suitable for streams with temporal
+// dependencies.
+func (e *Classification) KappaT() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.weight == 0 {
+		return 0.0
+	}
+	return relativeImprovement(e.correct/e.weight, e.noChange/e.weight)
+}
+
+// ConfusionMatrix returns the confusion matrix, labelled with
+// the values of the target attribute. The returned matrix can be
+// used to calculate per-class precision, recall and F1 scores
+// as well as their averages.
+func (e *Classification) ConfusionMatrix() *ConfusionMatrix {
+	e.mu.Lock()
+	weights := e.kappa.Matrix()
+	e.mu.Unlock()
+
+	var labels []string
+	if vals := e.model.Target().Values; vals != nil {
+		labels = append(labels, vals.Values()...)
+	}
+
+	// Make sure the matrix covers all labels
+	if d := len(labels) - len(weights); d > 0 {
+		weights = append(weights, make([][]float64, d)...)
+	}
+	for i, row := range weights {
+		if d := len(weights) - len(row); d > 0 {
+			weights[i] = append(row, make([]float64, d)...)
+		}
+	}
+	if d := len(weights) - len(labels); d > 0 {
+		labels = append(labels, make([]string, d)...)
+	}
+
+	return &ConfusionMatrix{Labels: labels, Weights: weights}
+}
+
+// relativeImprovement returns the improvement of an accuracy
+// over a baseline accuracy
+func relativeImprovement(accuracy, baseline float64) float64 {
+	if baseline == 1 {
+		return 0.0
+	}
+	return (accuracy - baseline) / (1 - baseline)
+}
@@ -2,8 +2,10 @@ package eval
 
 import (
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
 	"github.com/bsm/reason/testdata"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -33,6 +35,66 @@ var _ = Describe("Classification", func() {
 		Expect(subject.Kappa()).To(BeNumerically("~", 0.471, 0.001))
 		Expect(subject.Correct()).To(Equal(0.75))
 		Expect(subject.TotalWeight()).To(Equal(12.0))
+		Expect(subject.KappaM()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.KappaT()).To(BeNumerically("~", 0.4, 0.001))
 	})
 
+	It("should build confusion matrices", func() {
+		cm := subject.ConfusionMatrix()
+		Expect(cm).To(Equal(&ConfusionMatrix{
+			Labels: []string{"yes", "no"},
+			Weights: [][]float64{
+				{6, 2},
+				{1, 3},
+			},
+		}))
+		Expect(cm.Len()).To(Equal(2))
+		Expect(cm.Support(0)).To(Equal(8.0))
+		Expect(cm.Predicted(0)).To(Equal(7.0))
+
+		Expect(cm.Precision(0)).To(BeNumerically("~", 0.857, 0.001))
+		Expect(cm.Recall(0)).To(BeNumerically("~", 0.750, 0.001))
+		Expect(cm.F1(0)).To(BeNumerically("~", 0.800, 0.001))
+		Expect(cm.Precision(1)).To(BeNumerically("~", 0.600, 0.001))
+		Expect(cm.Recall(1)).To(BeNumerically("~", 0.750, 0.001))
+		Expect(cm.F1(1)).To(BeNumerically("~", 0.667, 0.001))
+		Expect(cm.Precision(2)).To(Equal(0.0))
+	})
+
+	It("should detect drifts", func() {
+		yes := core.Prediction{{AttributeValue: 0, Votes: 1}}
+		inst := core.MapInstance{"play": "yes"}
+
+		subject = NewClassification(model)
+		detected, warning := subject.Drift()
+		Expect(detected).To(BeFalse())
+		Expect(warning).To(BeFalse())
+
+		subject.SetDetector(drift.NewHDDM(0, 0))
+		for i := 0; i < 1000; i++ {
+			subject.Record(inst, yes)
+			detected, _ = subject.Drift()
+			Expect(detected).To(BeFalse())
+		}
+
+		for i := 0; i < 100 && !detected; i++ {
+			subject.Record(core.MapInstance{"play": "no"}, yes)
+			detected, _ = subject.Drift()
+		}
+		Expect(detected).To(BeTrue())
+	})
+
+	DescribeTable("should average per-class metrics",
+		func(avg Average, precision, recall, f1 float64) {
+			p, r, f := subject.ConfusionMatrix().Averages(avg)
+			Expect(p).To(BeNumerically("~", precision, 0.001))
+			Expect(r).To(BeNumerically("~", recall, 0.001))
+			Expect(f).To(BeNumerically("~", f1, 0.001))
+		},
+
+		Entry("macro", AverageMacro, 0.729, 0.750, 0.733),
+		Entry("micro", AverageMicro, 0.750, 0.750, 0.750),
+		Entry("weighted", AverageWeighted, 0.771, 0.750, 0.756),
+	)
+
 })
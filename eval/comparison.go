@@ -0,0 +1,317 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/internal/stats"
+)
+
+// ComparisonConfig configures model comparisons
+type ComparisonConfig struct {
+	// The number of instances per window. The Wilcoxon signed-rank and
+	// the Friedman tests compare the mean errors of learners per window.
+	// Default: 100
+	WindowSize int
+}
+
+func (c *ComparisonConfig) norm() {
+	if c.WindowSize <= 0 {
+		c.WindowSize = 100
+	}
+}
+
+// Comparison records paired predictions of two or more learners on the
+// same stream of instances and performs statistical tests to determine
+// whether differences in their performance are significant. Errors are 0/1
+// losses for classifications and absolute errors for regressions. See
+// "Statistical Comparisons of Classifiers over Multiple Data Sets" by
+// Janez Demšar (2006).
+type Comparison struct {
+	conf  *ComparisonConfig
+	model *core.Model
+	size  int
+
+	// discordant[i][j] holds the weight of instances
+	// learner i classified correctly and learner j did not
+	discordant [][]float64
+
+	// current holds the weighted error sums of the current window,
+	// windows holds the mean errors of all completed windows
+	current       []float64
+	currentWeight float64
+	currentCount  int
+	windows       [][]float64
+
+	mu sync.Mutex
+}
+
+// NewComparison inits a new comparison of n learners
+func NewComparison(model *core.Model, n int, conf *ComparisonConfig) *Comparison {
+	if conf == nil {
+		conf = new(ComparisonConfig)
+	}
+	conf.norm()
+
+	discordant := make([][]float64, n)
+	for i := range discordant {
+		discordant[i] = make([]float64, n)
+	}
+
+	return &Comparison{
+		conf:       conf,
+		model:      model,
+		size:       n,
+		discordant: discordant,
+		current:    make([]float64, n),
+	}
+}
+
+// Record records the predictions of all learners for an instance, in the
+// order of learners. For classifications, missing predictions are
+// considered wrong. For regressions, instances are ignored unless all
+// learners made a prediction.
+func (c *Comparison) Record(inst core.Instance, predictions ...core.Prediction) {
+	av := c.model.Target().Value(inst)
+	if av.IsMissing() {
+		return
+	}
+
+	errs := make([]float64, c.size)
+	for i := range errs {
+		var prediction core.Prediction
+		if i < len(predictions) {
+			prediction = predictions[i]
+		}
+
+		if c.model.IsRegression() {
+			pv := prediction.Top()
+			if pv.IsMissing() {
+				return
+			}
+			errs[i] = math.Abs(av.Value() - pv.Value())
+		} else if prediction.Index() != av.Index() {
+			errs[i] = 1
+		}
+	}
+
+	c.record(errs, inst.GetInstanceWeight())
+}
+
+func (c *Comparison) record(errs []float64, weight float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.model.IsRegression() {
+		for i, ei := range errs {
+			for j, ej := range errs {
+				if ei == 0 && ej != 0 {
+					c.discordant[i][j] += weight
+				}
+			}
+		}
+	}
+
+	for i, err := range errs {
+		c.current[i] += err * weight
+	}
+	c.currentWeight += weight
+
+	if c.currentCount++; c.currentCount == c.conf.WindowSize {
+		means := make([]float64, c.size)
+		if c.currentWeight > 0 {
+			for i, sum := range c.current {
+				means[i] = sum / c.currentWeight
+			}
+		}
+		c.windows = append(c.windows, means)
+
+		c.current = make([]float64, c.size)
+		c.currentWeight = 0
+		c.currentCount = 0
+	}
+}
+
+// NumWindows returns the number of completed windows
+func (c *Comparison) NumWindows() int {
+	c.mu.Lock()
+	n := len(c.windows)
+	c.mu.Unlock()
+	return n
+}
+
+// McNemar performs McNemar's test (with continuity correction) on the
+// classifications of learners i and j and returns the chi-square statistic
+// and the two-sided p-value. A low p-value indicates that the learners
+// perform differently. Returns NaN for regressions.
+func (c *Comparison) McNemar(i, j int) (statistic, pvalue float64) {
+	if c.model.IsRegression() {
+		return math.NaN(), math.NaN()
+	}
+
+	c.mu.Lock()
+	b, d := c.discordant[i][j], c.discordant[j][i]
+	c.mu.Unlock()
+
+	if b+d == 0 {
+		return 0, 1
+	}
+
+	z := math.Max(math.Abs(b-d)-1, 0) / math.Sqrt(b+d)
+	return z * z, 2 * stats.StdNormal.CDF(-z)
+}
+
+// Wilcoxon performs the Wilcoxon signed-rank test on the per-window mean
+// errors of learners i and j and returns the test statistic (the smaller
+// of the two rank sums) and the two-sided p-value, based on the normal
+// approximation. A low p-value indicates that the learners perform
+// differently.
+func (c *Comparison) Wilcoxon(i, j int) (statistic, pvalue float64) {
+	c.mu.Lock()
+	diffs := make([]float64, 0, len(c.windows))
+	for _, w := range c.windows {
+		if d := w[i] - w[j]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(diffs) == 0 {
+		return 0, 1
+	}
+
+	abs := make([]float64, len(diffs))
+	for k, d := range diffs {
+		abs[k] = math.Abs(d)
+	}
+
+	var pos, neg float64
+	for k, r := range rank(abs) {
+		if diffs[k] > 0 {
+			pos += r
+		} else {
+			neg += r
+		}
+	}
+
+	n := float64(len(diffs))
+	mean := n * (n + 1) / 4
+	sd := math.Sqrt(n * (n + 1) * (2*n + 1) / 24)
+	statistic = math.Min(pos, neg)
+	return statistic, math.Min(2*stats.StdNormal.CDF((statistic-mean)/sd), 1)
+}
+
+// Ranks returns the average ranks of all learners across all windows,
+// the learner with the lowest error is ranked 1
+func (c *Comparison) Ranks() []float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ranks()
+}
+
+// Friedman performs the Friedman test on the per-window mean errors of all
+// learners and returns the chi-square statistic and the p-value, based on
+// the Wilson-Hilferty approximation of the chi-square distribution. A low
+// p-value indicates that at least one learner performs differently.
+func (c *Comparison) Friedman() (statistic, pvalue float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, k := float64(len(c.windows)), float64(c.size)
+	if n == 0 || k < 2 {
+		return 0, 1
+	}
+
+	sum := 0.0
+	for _, r := range c.ranks() {
+		sum += r * r
+	}
+	statistic = 12 * n / (k * (k + 1)) * (sum - k*(k+1)*(k+1)/4)
+	return statistic, chiSquareSF(statistic, k-1)
+}
+
+// CriticalDifference returns the critical difference of the Nemenyi
+// post-hoc test at a significance level alpha, which can be either 0.05 or
+// 0.1. Two learners perform significantly differently if their average
+// ranks differ by at least the critical difference. Returns NaN for
+// unsupported significance levels or more than 10 learners.
+func (c *Comparison) CriticalDifference(alpha float64) float64 {
+	c.mu.Lock()
+	n, k := len(c.windows), c.size
+	c.mu.Unlock()
+
+	var table []float64
+	switch alpha {
+	case 0.05:
+		table = nemenyi05
+	case 0.1:
+		table = nemenyi10
+	}
+	if k < 2 || k-2 >= len(table) || n == 0 {
+		return math.NaN()
+	}
+	return table[k-2] * math.Sqrt(float64(k*(k+1))/float64(6*n))
+}
+
+func (c *Comparison) ranks() []float64 {
+	sums := make([]float64, c.size)
+	if len(c.windows) == 0 {
+		return sums
+	}
+
+	for _, w := range c.windows {
+		for i, r := range rank(w) {
+			sums[i] += r
+		}
+	}
+	for i := range sums {
+		sums[i] /= float64(len(c.windows))
+	}
+	return sums
+}
+
+// --------------------------------------------------------------------
+
+// critical values of the studentized range statistic divided by sqrt(2),
+// for 2 to 10 learners
+var (
+	nemenyi05 = []float64{1.960, 2.343, 2.569, 2.728, 2.850, 2.949, 3.031, 3.102, 3.164}
+	nemenyi10 = []float64{1.645, 2.052, 2.291, 2.459, 2.589, 2.693, 2.780, 2.855, 2.920}
+)
+
+// rank returns the (1-based) ranks of values in ascending order,
+// ties receive the average of their ranks
+func rank(vals []float64) []float64 {
+	order := make([]int, len(vals))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return vals[order[i]] < vals[order[j]] })
+
+	ranks := make([]float64, len(vals))
+	for i := 0; i < len(order); {
+		j := i + 1
+		for j < len(order) && vals[order[j]] == vals[order[i]] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			ranks[order[k]] = float64(i+j+1) / 2
+		}
+		i = j
+	}
+	return ranks
+}
+
+// chiSquareSF returns the survival function of the chi-square distribution
+// with df degrees of freedom, using the Wilson-Hilferty approximation
+func chiSquareSF(x, df float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	v := 2 / (9 * df)
+	z := (math.Cbrt(x/df) - (1 - v)) / math.Sqrt(v)
+	return stats.StdNormal.CDF(-z)
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Comparison", func() {
+
+	It("should perform McNemar's test", func() {
+		yes := core.Prediction{{AttributeValue: 0, Votes: 1}}
+		no := core.Prediction{{AttributeValue: 1, Votes: 1}}
+		inst := core.MapInstance{"play": "yes"}
+
+		subject := NewComparison(testdata.ClassificationModel(), 2, nil)
+		for i := 0; i < 10; i++ {
+			subject.Record(inst, yes, no)
+		}
+		subject.Record(inst, no, yes)
+		subject.Record(inst, nil, yes)
+		for i := 0; i < 5; i++ {
+			subject.Record(inst, yes, yes)
+		}
+
+		stat, p := subject.McNemar(0, 1)
+		Expect(stat).To(BeNumerically("~", 4.083, 0.001))
+		Expect(p).To(BeNumerically("~", 0.043, 0.001))
+
+		stat, p = subject.McNemar(1, 0)
+		Expect(stat).To(BeNumerically("~", 4.083, 0.001))
+		Expect(p).To(BeNumerically("~", 0.043, 0.001))
+
+		stat, p = subject.McNemar(0, 0)
+		Expect(stat).To(Equal(0.0))
+		Expect(p).To(Equal(1.0))
+	})
+
+	It("should perform Wilcoxon signed-rank tests", func() {
+		subject := NewComparison(testdata.RegressionModel(), 2, &ComparisonConfig{WindowSize: 1})
+		for i := 1; i <= 10; i++ {
+			subject.Record(core.MapInstance{"hours": 20.0},
+				core.Prediction{{AttributeValue: core.AttributeValue(20 + i), Votes: 1}},
+				core.Prediction{{AttributeValue: 20, Votes: 1}},
+			)
+		}
+		subject.Record(core.MapInstance{"hours": 20.0}, nil, nil)
+		Expect(subject.NumWindows()).To(Equal(10))
+
+		stat, p := subject.Wilcoxon(0, 1)
+		Expect(stat).To(Equal(0.0))
+		Expect(p).To(BeNumerically("~", 0.005, 0.001))
+
+		stat, p = subject.McNemar(0, 1)
+		Expect(math.IsNaN(stat)).To(BeTrue())
+		Expect(math.IsNaN(p)).To(BeTrue())
+	})
+
+	It("should perform Friedman/Nemenyi tests", func() {
+		subject := NewComparison(testdata.RegressionModel(), 3, &ComparisonConfig{WindowSize: 2})
+		for i := 0; i < 20; i++ {
+			subject.Record(core.MapInstance{"hours": 20.0},
+				core.Prediction{{AttributeValue: 19, Votes: 1}},
+				core.Prediction{{AttributeValue: 22, Votes: 1}},
+				core.Prediction{{AttributeValue: 17, Votes: 1}},
+			)
+		}
+		Expect(subject.NumWindows()).To(Equal(10))
+		Expect(subject.Ranks()).To(Equal([]float64{1, 2, 3}))
+
+		stat, p := subject.Friedman()
+		Expect(stat).To(BeNumerically("~", 20, 0.001))
+		Expect(p).To(BeNumerically("<", 0.001))
+
+		Expect(subject.CriticalDifference(0.05)).To(BeNumerically("~", 1.048, 0.001))
+		Expect(subject.CriticalDifference(0.1)).To(BeNumerically("~", 0.918, 0.001))
+		Expect(math.IsNaN(subject.CriticalDifference(0.2))).To(BeTrue())
+	})
+
+	It("should rank", func() {
+		Expect(rank([]float64{0.3, 0.1, 0.3, 0.2})).To(Equal([]float64{3.5, 1, 3.5, 2}))
+		Expect(chiSquareSF(3.841, 1)).To(BeNumerically("~", 0.05, 0.005))
+	})
+
+})
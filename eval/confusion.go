@@ -0,0 +1,130 @@
+package eval
+
+// Average is a method for averaging per-class metrics
+type Average uint8
+
+const (
+	// AverageMacro is the unweighted mean of the per-class metrics
+	AverageMacro Average = iota
+	// AverageMicro calculates metrics globally, from the total true
+	// positives, false positives and false negatives
+	AverageMicro
+	// AverageWeighted is the mean of the per-class metrics, weighted
+	// by the support of each class
+	AverageWeighted
+)
+
+// ConfusionMatrix contains the observed weights of actual vs
+// predicted classes
+type ConfusionMatrix struct {
+	// Labels are the class labels
+	Labels []string
+	// Weights are indexed by actual and predicted class
+	Weights [][]float64
+}
+
+// Len returns the number of classes
+func (m *ConfusionMatrix) Len() int { return len(m.Weights) }
+
+// Support returns the total weight of actual instances of a class
+func (m *ConfusionMatrix) Support(class int) float64 {
+	if class < 0 || class >= len(m.Weights) {
+		return 0
+	}
+
+	sum := 0.0
+	for _, w := range m.Weights[class] {
+		sum += w
+	}
+	return sum
+}
+
+// Predicted returns the total weight of instances predicted as a class
+func (m *ConfusionMatrix) Predicted(class int) float64 {
+	if class < 0 || class >= len(m.Weights) {
+		return 0
+	}
+
+	sum := 0.0
+	for _, row := range m.Weights {
+		sum += row[class]
+	}
+	return sum
+}
+
+// Precision returns the fraction of correct predictions of a class
+func (m *ConfusionMatrix) Precision(class int) float64 {
+	if predicted := m.Predicted(class); predicted > 0 {
+		return m.Weights[class][class] / predicted
+	}
+	return 0
+}
+
+// Recall returns the fraction of actual instances of a class
+// that were correctly predicted
+func (m *ConfusionMatrix) Recall(class int) float64 {
+	if support := m.Support(class); support > 0 {
+		return m.Weights[class][class] / support
+	}
+	return 0
+}
+
+// F1 returns the harmonic mean of precision and recall of a class
+func (m *ConfusionMatrix) F1(class int) float64 {
+	return f1(m.Precision(class), m.Recall(class))
+}
+
+// Averages returns averaged precision, recall and F1 scores. Classes
+// which were neither observed nor predicted are ignored.
+func (m *ConfusionMatrix) Averages(avg Average) (precision, recall, f1score float64) {
+	if avg == AverageMicro {
+		// For single-label classifications, the total false positives
+		// always equal the total false negatives
+		total, correct := 0.0, 0.0
+		for i, row := range m.Weights {
+			for _, w := range row {
+				total += w
+			}
+			correct += row[i]
+		}
+		if total == 0 {
+			return 0, 0, 0
+		}
+		score := correct / total
+		return score, score, score
+	}
+
+	sum := 0.0
+	for i := range m.Weights {
+		support := m.Support(i)
+
+		var weight float64
+		switch avg {
+		case AverageWeighted:
+			weight = support
+		default:
+			if support > 0 || m.Predicted(i) > 0 {
+				weight = 1
+			}
+		}
+		if weight == 0 {
+			continue
+		}
+
+		sum += weight
+		precision += weight * m.Precision(i)
+		recall += weight * m.Recall(i)
+		f1score += weight * m.F1(i)
+	}
+	if sum == 0 {
+		return 0, 0, 0
+	}
+	return precision / sum, recall / sum, f1score / sum
+}
+
+func f1(precision, recall float64) float64 {
+	if sum := precision + recall; sum > 0 {
+		return 2 * precision * recall / sum
+	}
+	return 0
+}
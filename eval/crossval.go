@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/dataset"
+)
+
+// CrossValidation performs a distributed k-fold cross-validation on
+// streams of instances. Instances are assigned to folds in a round-robin
+// fashion. Each of the k learners is tested on the instances of one fold
+// and trained on the instances of all other folds. See "Efficient Online
+// Evaluation of Big Data Stream Classifiers" by Albert Bifet et al. (2015).
+type CrossValidation struct {
+	model    *core.Model
+	learners []Learner
+	evals    []Evaluator
+
+	count int
+}
+
+// NewCrossValidation inits a new k-fold cross-validation, using a
+// factory function to create k learners. Default k: 10
+func NewCrossValidation(model *core.Model, k int, newLearner func() Learner) *CrossValidation {
+	if k <= 0 {
+		k = 10
+	}
+
+	learners := make([]Learner, k)
+	evals := make([]Evaluator, k)
+	for i := range learners {
+		learners[i] = newLearner()
+		evals[i] = NewEvaluator(model)
+	}
+
+	return &CrossValidation{
+		model:    model,
+		learners: learners,
+		evals:    evals,
+	}
+}
+
+// Run processes all instances of the stream
+func (c *CrossValidation) Run(stream Stream) error {
+	for stream.Next() {
+		c.Process(stream.Instance())
+	}
+	return stream.Err()
+}
+
+// Process processes a single instance. The learner of the instance's fold
+// is tested, all other learners are trained.
+func (c *CrossValidation) Process(inst core.Instance) {
+	fold := c.count % len(c.learners)
+	c.count++
+
+	for i, learner := range c.learners {
+		if i == fold {
+			prediction := learner.Predict(inst)
+			c.evals[i].Record(inst, prediction)
+			prediction.Release()
+		} else {
+			learner.Train(inst)
+		}
+	}
+}
+
+// Learners returns the learners, one per fold
+func (c *CrossValidation) Learners() []Learner { return c.learners }
+
+// Evaluators returns the evaluators, one per fold
+func (c *CrossValidation) Evaluators() []Evaluator { return c.evals }
+
+// CrossValidateCSV performs a k-fold cross-validation on the instances
+// of a CSV file. Columns must be in the order of the model's predictors,
+// followed by the target. See dataset.CSVReader for custom layouts.
+func CrossValidateCSV(fname string, model *core.Model, k int, newLearner func() Learner) ([]Evaluator, error) {
+	stream, err := dataset.OpenCSV(fname, model, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	c := NewCrossValidation(model, k, newLearner)
+	if err := c.Run(stream); err != nil {
+		return nil, err
+	}
+	return c.Evaluators(), nil
+}
@@ -0,0 +1,46 @@
+package eval
+
+import (
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CrossValidation", func() {
+
+	It("should cross-validate", func() {
+		model := testdata.BigRegressionModel()
+		evals, err := CrossValidateCSV("../testdata/bigreg.csv", model, 5, func() Learner {
+			return newMeanLearner(model)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evals).To(HaveLen(5))
+
+		for _, e := range evals {
+			Expect(e.TotalWeight()).To(BeNumerically("~", 20000, 1))
+			Expect(e.(*Regression).RMSE()).To(BeNumerically("~", 0.68, 0.05))
+		}
+	})
+
+	It("should train all but one learner", func() {
+		model := testdata.RegressionModel()
+		subject := NewCrossValidation(model, 3, func() Learner { return newMeanLearner(model) })
+		for i := 0; i < 7; i++ {
+			subject.Process(core.MapInstance{"hours": float64(i)})
+		}
+
+		var weights []float64
+		for _, l := range subject.Learners() {
+			weights = append(weights, l.(*meanLearner).weight)
+		}
+		Expect(weights).To(Equal([]float64{4, 5, 5}))
+
+		var tested []float64
+		for _, e := range subject.Evaluators() {
+			tested = append(tested, e.TotalWeight())
+		}
+		Expect(tested).To(Equal([]float64{2, 2, 2}))
+	})
+
+})
@@ -9,3 +9,45 @@ type Evaluator interface {
 	// TotalWeight records the total instance weight observed
 	TotalWeight() float64
 }
+
+// NewEvaluator inits a new basic evaluator, either a *Classification or
+// a *Regression, depending on the model
+func NewEvaluator(model *core.Model) Evaluator {
+	if model.IsRegression() {
+		return NewRegression(model)
+	}
+	return NewClassification(model)
+}
+
+// Learner implementations can be trained and can make predictions
+type Learner interface {
+	// Train trains the learner with an instance
+	Train(core.Instance)
+	// Predict returns a prediction for an instance
+	Predict(core.Instance) core.Prediction
+}
+
+// NewLearner wraps train and predict functions as a Learner. Example:
+//
+//	learner := eval.NewLearner(func(inst core.Instance) { tree.Train(inst) }, tree.Predict)
+func NewLearner(train func(core.Instance), predict func(core.Instance) core.Prediction) Learner {
+	return learnerFuncs{train: train, predict: predict}
+}
+
+type learnerFuncs struct {
+	train   func(core.Instance)
+	predict func(core.Instance) core.Prediction
+}
+
+func (l learnerFuncs) Train(inst core.Instance)                   { l.train(inst) }
+func (l learnerFuncs) Predict(inst core.Instance) core.Prediction { return l.predict(inst) }
+
+// Stream implementations provide a sequence of instances
+type Stream interface {
+	// Next advances the stream, returns false when exhausted or on errors
+	Next() bool
+	// Instance returns the current instance
+	Instance() core.Instance
+	// Err returns the first error encountered
+	Err() error
+}
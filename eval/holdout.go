@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/dataset"
+)
+
+// HoldoutConfig configures holdout evaluations
+type HoldoutConfig struct {
+	// The number of training instances between evaluations
+	// Default: 1000
+	Interval int
+
+	// An optional callback which is invoked with each new result
+	OnResult func(HoldoutResult)
+}
+
+func (c *HoldoutConfig) norm() {
+	if c.Interval <= 0 {
+		c.Interval = 1000
+	}
+}
+
+// HoldoutResult is the result of a holdout evaluation
+type HoldoutResult struct {
+	// NumInstances is the number of instances trained so far
+	NumInstances int
+	// Evaluator contains the performance on the holdout set
+	Evaluator Evaluator
+}
+
+// Holdout trains learners on streams of instances and periodically
+// evaluates them on a fixed holdout set.
+type Holdout struct {
+	conf    *HoldoutConfig
+	model   *core.Model
+	testSet []core.Instance
+
+	count   int
+	results []HoldoutResult
+}
+
+// NewHoldout inits a new evaluation with a holdout set
+func NewHoldout(model *core.Model, testSet []core.Instance, conf *HoldoutConfig) *Holdout {
+	if conf == nil {
+		conf = new(HoldoutConfig)
+	}
+	conf.norm()
+
+	return &Holdout{
+		conf:    conf,
+		model:   model,
+		testSet: testSet,
+	}
+}
+
+// Run trains the learner on all instances of the stream and evaluates it
+// on the holdout set after every interval and once the stream is exhausted.
+func (h *Holdout) Run(learner Learner, stream Stream) error {
+	for stream.Next() {
+		learner.Train(stream.Instance())
+
+		if h.count++; h.count%h.conf.Interval == 0 {
+			h.evaluate(learner)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+
+	if h.count%h.conf.Interval != 0 {
+		h.evaluate(learner)
+	}
+	return nil
+}
+
+// Evaluate evaluates the learner on the holdout set
+func (h *Holdout) Evaluate(learner Learner) Evaluator {
+	e := NewEvaluator(h.model)
+	for _, inst := range h.testSet {
+		prediction := learner.Predict(inst)
+		e.Record(inst, prediction)
+		prediction.Release()
+	}
+	return e
+}
+
+// Results returns the results of all evaluations
+func (h *Holdout) Results() []HoldoutResult { return h.results }
+
+func (h *Holdout) evaluate(learner Learner) {
+	res := HoldoutResult{NumInstances: h.count, Evaluator: h.Evaluate(learner)}
+	h.results = append(h.results, res)
+	if h.conf.OnResult != nil {
+		h.conf.OnResult(res)
+	}
+}
+
+// HoldoutCSV opens a CSV file, uses the first testSize instances as the
+// holdout set and trains the learner on the remaining instances. Columns
+// must be in the order of the model's predictors, followed by the target.
+// See dataset.CSVReader for custom layouts.
+func HoldoutCSV(fname string, model *core.Model, testSize int, learner Learner, conf *HoldoutConfig) ([]HoldoutResult, error) {
+	stream, err := dataset.OpenCSV(fname, model, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	testSet, err := stream.ReadN(testSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewHoldout(model, testSet, conf)
+	if err := h.Run(learner, stream); err != nil {
+		return nil, err
+	}
+	return h.Results(), nil
+}
@@ -0,0 +1,62 @@
+package eval
+
+import (
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Holdout", func() {
+
+	It("should evaluate periodically", func() {
+		model := testdata.BigRegressionModel()
+		learner := newMeanLearner(model)
+
+		var n int
+		results, err := HoldoutCSV("../testdata/bigreg.csv", model, 1000, learner, &HoldoutConfig{
+			Interval: 20000,
+			OnResult: func(_ HoldoutResult) { n++ },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(5))
+		Expect(n).To(Equal(5))
+		Expect(learner.weight).To(Equal(99000.0))
+
+		for i, res := range results {
+			Expect(res.Evaluator.TotalWeight()).To(Equal(1000.0))
+			Expect(res.Evaluator.(*Regression).RMSE()).To(BeNumerically("~", 0.68, 0.01))
+			if i < 4 {
+				Expect(res.NumInstances).To(Equal((i + 1) * 20000))
+			}
+		}
+		Expect(results[4].NumInstances).To(Equal(99000))
+	})
+
+	It("should fail on bad files", func() {
+		_, err := HoldoutCSV("../testdata/missing.csv", testdata.BigRegressionModel(), 1000, nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
+
+// meanLearner is a simple learner which predicts the mean target value
+type meanLearner struct {
+	model       *core.Model
+	sum, weight float64
+}
+
+func newMeanLearner(model *core.Model) *meanLearner { return &meanLearner{model: model} }
+
+func (l *meanLearner) Train(inst core.Instance) {
+	weight := inst.GetInstanceWeight()
+	l.sum += l.model.Target().Value(inst).Value() * weight
+	l.weight += weight
+}
+
+func (l *meanLearner) Predict(_ core.Instance) core.Prediction {
+	if l.weight == 0 {
+		return nil
+	}
+	return core.Prediction{{AttributeValue: core.AttributeValue(l.sum / l.weight), Votes: l.weight}}
+}
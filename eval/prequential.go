@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/bsm/reason/core"
+)
+
+// PrequentialConfig configures prequential evaluations
+type PrequentialConfig struct {
+	// The number of most recent instances included in windowed metrics
+	// Default: 1000
+	WindowSize int
+
+	// The fading factor applied to faded metrics
+	// Default: 0.999
+	FadingFactor float64
+
+	// The number of instances between learning curve points.
+	// To disable, set to <0.
+	// Default: 1000
+	Interval int
+
+	// An optional callback which is invoked with each new
+	// learning curve point
+	OnCurvePoint func(CurvePoint)
+}
+
+func (c *PrequentialConfig) norm() {
+	if c.WindowSize <= 0 {
+		c.WindowSize = 1000
+	}
+	if c.FadingFactor <= 0 || c.FadingFactor > 1 {
+		c.FadingFactor = 0.999
+	}
+	if c.Interval == 0 {
+		c.Interval = 1000
+	}
+}
+
+// Metrics contain evaluation metrics
+type Metrics struct {
+	// Weight is the (faded) instance weight the metrics are based on
+	Weight float64
+
+	// Correct is the fraction of correct classifications and
+	// Kappa is Cohen's kappa (classifications only).
+	// Kappa is not available for faded metrics.
+	Correct, Kappa float64
+
+	// MAE is the mean absolute error and RMSE the root mean
+	// square error (regressions only)
+	MAE, RMSE float64
+}
+
+// CurvePoint is a point of a learning curve
+type CurvePoint struct {
+	// NumInstances is the number of instances evaluated so far
+	NumInstances int
+
+	Cumulative, Windowed, Faded Metrics
+}
+
+// Prequential evaluates learners on streams of instances using the
+// test-then-train approach. Each instance is used to test the learner
+// before it is used for training. Metrics are reported cumulatively,
+// over a sliding window of recent instances and with an exponential
+// fading factor.
+type Prequential struct {
+	conf  *PrequentialConfig
+	model *core.Model
+
+	classification *Classification
+	regression     *Regression
+
+	window []prequentialEntry
+	pos    int
+	count  int
+
+	fadedWeight, fadedCorrect, fadedAbs, fadedSq float64
+
+	curve []CurvePoint
+}
+
+// prequentialEntry holds an actual and a predicted value (or index)
+type prequentialEntry struct{ actual, predicted, weight float64 }
+
+// NewPrequential inits a new evaluation
+func NewPrequential(model *core.Model, conf *PrequentialConfig) *Prequential {
+	if conf == nil {
+		conf = new(PrequentialConfig)
+	}
+	conf.norm()
+
+	p := &Prequential{
+		conf:   conf,
+		model:  model,
+		window: make([]prequentialEntry, 0, conf.WindowSize),
+	}
+	if model.IsRegression() {
+		p.regression = NewRegression(model)
+	} else {
+		p.classification = NewClassification(model)
+	}
+	return p
+}
+
+// Run runs the evaluation: for each instance of the stream, it
+// tests the learner first and then trains it.
+func (p *Prequential) Run(learner Learner, stream Stream) error {
+	for stream.Next() {
+		inst := stream.Instance()
+
+		prediction := learner.Predict(inst)
+		p.Record(inst, prediction)
+		prediction.Release()
+
+		learner.Train(inst)
+	}
+	return stream.Err()
+}
+
+// Record records a prediction
+func (p *Prequential) Record(inst core.Instance, prediction core.Prediction) {
+	av := p.model.Target().Value(inst)
+	if av.IsMissing() {
+		return
+	}
+
+	weight := inst.GetInstanceWeight()
+	entry := prequentialEntry{weight: weight}
+	if p.regression != nil {
+		pv := prediction.Top()
+		if pv.IsMissing() {
+			return
+		}
+		entry.actual, entry.predicted = av.Value(), pv.Value()
+		p.regression.record(entry.actual, entry.predicted, pv.Variance, weight)
+	} else {
+		pi := prediction.Index()
+		if pi < 0 {
+			return
+		}
+		entry.actual, entry.predicted = float64(av.Index()), float64(pi)
+		p.classification.record(pi, av.Index(), weight)
+	}
+
+	// Update the sliding window
+	if len(p.window) < p.conf.WindowSize {
+		p.window = append(p.window, entry)
+	} else {
+		p.window[p.pos] = entry
+		p.pos = (p.pos + 1) % p.conf.WindowSize
+	}
+
+	// Update faded metrics
+	ff, diff := p.conf.FadingFactor, entry.actual-entry.predicted
+	p.fadedWeight = p.fadedWeight*ff + weight
+	p.fadedAbs = p.fadedAbs*ff + math.Abs(diff)*weight
+	p.fadedSq = p.fadedSq*ff + diff*diff*weight
+	p.fadedCorrect *= ff
+	if diff == 0 {
+		p.fadedCorrect += weight
+	}
+
+	// Emit learning curve points
+	if p.count++; p.conf.Interval > 0 && p.count%p.conf.Interval == 0 {
+		point := CurvePoint{
+			NumInstances: p.count,
+			Cumulative:   p.Cumulative(),
+			Windowed:     p.Windowed(),
+			Faded:        p.Faded(),
+		}
+		p.curve = append(p.curve, point)
+		if p.conf.OnCurvePoint != nil {
+			p.conf.OnCurvePoint(point)
+		}
+	}
+}
+
+// TotalWeight returns the total weight observed
+func (p *Prequential) TotalWeight() float64 {
+	if p.regression != nil {
+		return p.regression.TotalWeight()
+	}
+	return p.classification.TotalWeight()
+}
+
+// Classification returns the cumulative classification evaluator
+// (classifications only)
+func (p *Prequential) Classification() *Classification { return p.classification }
+
+// Regression returns the cumulative regression evaluator
+// (regressions only)
+func (p *Prequential) Regression() *Regression { return p.regression }
+
+// Curve returns the learning curve
+func (p *Prequential) Curve() []CurvePoint { return p.curve }
+
+// Cumulative returns metrics over all instances
+func (p *Prequential) Cumulative() Metrics {
+	if p.regression != nil {
+		return regressionMetrics(p.regression)
+	}
+	return classificationMetrics(p.classification)
+}
+
+// Windowed returns metrics over the most recent instances
+func (p *Prequential) Windowed() Metrics {
+	if p.regression != nil {
+		e := NewRegression(p.model)
+		for _, entry := range p.window {
+			e.record(entry.actual, entry.predicted, math.NaN(), entry.weight)
+		}
+		return regressionMetrics(e)
+	}
+
+	e := NewClassification(p.model)
+	for _, entry := range p.window {
+		e.record(int(entry.predicted), int(entry.actual), entry.weight)
+	}
+	return classificationMetrics(e)
+}
+
+// Faded returns metrics with exponentially decaying
+// weights of past instances
+func (p *Prequential) Faded() Metrics {
+	m := Metrics{Weight: p.fadedWeight}
+	if p.fadedWeight == 0 {
+		return m
+	}
+
+	if p.regression != nil {
+		m.MAE = p.fadedAbs / p.fadedWeight
+		m.RMSE = math.Sqrt(p.fadedSq / p.fadedWeight)
+	} else {
+		m.Correct = p.fadedCorrect / p.fadedWeight
+	}
+	return m
+}
+
+func classificationMetrics(e *Classification) Metrics {
+	return Metrics{
+		Weight:  e.TotalWeight(),
+		Correct: e.Correct(),
+		Kappa:   e.Kappa(),
+	}
+}
+
+func regressionMetrics(e *Regression) Metrics {
+	return Metrics{
+		Weight: e.TotalWeight(),
+		MAE:    e.MAE(),
+		RMSE:   e.RMSE(),
+	}
+}
@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prequential", func() {
+
+	It("should evaluate classifications", func() {
+		model := testdata.ClassificationModel()
+		insts := make([]core.Instance, 0, 4000)
+		for i := 0; i < cap(insts); i++ {
+			class := "yes"
+			if i >= 3000 && i%2 == 1 {
+				class = "no"
+			}
+			insts = append(insts, core.MapInstance{"play": class})
+		}
+
+		// predicts the most recently observed class
+		last := core.MissingValue()
+		learner := NewLearner(func(inst core.Instance) {
+			last = model.Target().Value(inst)
+		}, func(_ core.Instance) core.Prediction {
+			if last.IsMissing() {
+				return nil
+			}
+			return core.Prediction{{AttributeValue: last, Votes: 1}}
+		})
+
+		var points []CurvePoint
+		subject := NewPrequential(model, &PrequentialConfig{
+			OnCurvePoint: func(p CurvePoint) { points = append(points, p) },
+		})
+		Expect(subject.Run(learner, &sliceStream{insts: insts})).To(Succeed())
+		Expect(subject.TotalWeight()).To(Equal(3999.0))
+		Expect(subject.Classification().Correct()).To(BeNumerically("~", 0.750, 0.001))
+
+		Expect(subject.Cumulative().Correct).To(BeNumerically("~", 0.750, 0.001))
+		Expect(subject.Windowed()).To(Equal(Metrics{Weight: 1000, Correct: 0.001, Kappa: subject.Windowed().Kappa}))
+		Expect(subject.Faded().Correct).To(BeNumerically("<", 0.4))
+		Expect(subject.Faded().Weight).To(BeNumerically("~", 981.7, 0.1))
+
+		Expect(subject.Curve()).To(HaveLen(3))
+		Expect(points).To(HaveLen(3))
+		Expect(points[2].NumInstances).To(Equal(3000))
+		Expect(points[2].Windowed.Correct).To(Equal(1.0))
+	})
+
+	It("should evaluate regressions", func() {
+		model := testdata.RegressionModel()
+		insts := make([]core.Instance, 0, 200)
+		for i := 0; i < cap(insts); i++ {
+			hours := 1.0
+			if i >= 100 {
+				hours = 2.0
+			}
+			insts = append(insts, core.MapInstance{"hours": hours})
+		}
+
+		learner := NewLearner(func(_ core.Instance) {}, func(_ core.Instance) core.Prediction {
+			return core.Prediction{{AttributeValue: 0, Votes: 1}}
+		})
+
+		subject := NewPrequential(model, &PrequentialConfig{WindowSize: 10, FadingFactor: 0.9, Interval: -1})
+		Expect(subject.Run(learner, &sliceStream{insts: insts})).To(Succeed())
+		Expect(subject.Regression().MAE()).To(Equal(1.5))
+		Expect(subject.Cumulative().MAE).To(Equal(1.5))
+		Expect(subject.Windowed()).To(Equal(Metrics{Weight: 10, MAE: 2, RMSE: 2}))
+		Expect(subject.Faded().MAE).To(BeNumerically("~", 2.0, 0.001))
+		Expect(subject.Curve()).To(BeEmpty())
+	})
+
+})
+
+type sliceStream struct {
+	insts []core.Instance
+	pos   int
+}
+
+func (s *sliceStream) Next() bool {
+	if s.pos < len(s.insts) {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *sliceStream) Instance() core.Instance { return s.insts[s.pos-1] }
+func (s *sliceStream) Err() error              { return nil }
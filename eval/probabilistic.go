@@ -0,0 +1,281 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/bsm/reason/core"
+)
+
+// ProbabilisticConfig configures probabilistic evaluators
+type ProbabilisticConfig struct {
+	// The number of most recent instances used to calculate ROC-AUC
+	// Default: 1000
+	WindowSize int
+
+	// The number of (equal width) calibration bins
+	// Default: 10
+	NumBins int
+
+	// The index of the positive class of binary targets,
+	// used to calculate ROC-AUC. Must be either 0 or 1.
+	// Default: 0
+	PositiveClass int
+}
+
+func (c *ProbabilisticConfig) norm() {
+	if c.WindowSize <= 0 {
+		c.WindowSize = 1000
+	}
+	if c.NumBins <= 0 {
+		c.NumBins = 10
+	}
+	if c.PositiveClass < 0 || c.PositiveClass > 1 {
+		c.PositiveClass = 0
+	}
+}
+
+// CalibrationBin is a bin of a reliability diagram
+type CalibrationBin struct {
+	// Lower and upper bound of the bin's confidence range
+	Lower, Upper float64
+	// Weight is the total instance weight in the bin
+	Weight float64
+	// Confidence is the mean probability of the predicted class
+	Confidence float64
+	// Accuracy is the observed fraction of correct predictions
+	Accuracy float64
+}
+
+// Probabilistic evaluates classifications by normalising the votes of
+// predictions into class probabilities. It tracks log-loss, Brier score,
+// calibration of the predicted class and, for binary targets, the ROC-AUC
+// over a sliding window.
+type Probabilistic struct {
+	conf  *ProbabilisticConfig
+	model *core.Model
+
+	weight, logLoss, brier float64
+
+	binWeights, binConfidence, binCorrect []float64
+
+	window []scoredEntry
+	pos    int
+
+	mu sync.Mutex
+}
+
+// scoredEntry holds the positive score of a binary prediction
+type scoredEntry struct {
+	score, weight float64
+	positive      bool
+}
+
+// minProbability is used to clip probabilities when calculating log-loss
+const minProbability = 1e-15
+
+// NewProbabilistic inits a new evaluator
+func NewProbabilistic(model *core.Model, conf *ProbabilisticConfig) *Probabilistic {
+	if conf == nil {
+		conf = new(ProbabilisticConfig)
+	}
+	conf.norm()
+
+	return &Probabilistic{
+		conf:          conf,
+		model:         model,
+		binWeights:    make([]float64, conf.NumBins),
+		binConfidence: make([]float64, conf.NumBins),
+		binCorrect:    make([]float64, conf.NumBins),
+		window:        make([]scoredEntry, 0, conf.WindowSize),
+	}
+}
+
+// Record records a prediction. Predictions without votes are treated as
+// uniform distributions across all classes.
+func (e *Probabilistic) Record(inst core.Instance, prediction core.Prediction) {
+	av := e.model.Target().Value(inst)
+	if av.IsMissing() {
+		return
+	}
+
+	e.record(e.probabilities(prediction), av.Index(), inst.GetInstanceWeight())
+}
+
+func (e *Probabilistic) record(probs []float64, ai int, weight float64) {
+	for len(probs) <= ai {
+		probs = append(probs, 0)
+	}
+
+	// Find the predicted class
+	top := 0
+	for i, p := range probs {
+		if p > probs[top] {
+			top = i
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.weight += weight
+	e.logLoss -= math.Log(math.Min(math.Max(probs[ai], minProbability), 1-minProbability)) * weight
+	for i, p := range probs {
+		y := 0.0
+		if i == ai {
+			y = 1
+		}
+		e.brier += (p - y) * (p - y) * weight
+	}
+
+	bin := int(probs[top] * float64(e.conf.NumBins))
+	if bin >= e.conf.NumBins {
+		bin = e.conf.NumBins - 1
+	}
+	e.binWeights[bin] += weight
+	e.binConfidence[bin] += probs[top] * weight
+	if top == ai {
+		e.binCorrect[bin] += weight
+	}
+
+	if len(probs) == 2 {
+		entry := scoredEntry{score: probs[e.conf.PositiveClass], weight: weight, positive: ai == e.conf.PositiveClass}
+		if len(e.window) < e.conf.WindowSize {
+			e.window = append(e.window, entry)
+		} else {
+			e.window[e.pos] = entry
+			e.pos = (e.pos + 1) % e.conf.WindowSize
+		}
+	}
+}
+
+// TotalWeight returns the total weight observed
+func (e *Probabilistic) TotalWeight() float64 {
+	e.mu.Lock()
+	weight := e.weight
+	e.mu.Unlock()
+	return weight
+}
+
+// LogLoss returns the mean negative log-likelihood of the actual classes
+func (e *Probabilistic) LogLoss() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.weight == 0 {
+		return 0.0
+	}
+	return e.logLoss / e.weight
+}
+
+// BrierScore returns the mean squared difference between the predicted
+// probabilities and the actual outcomes, summed across all classes
+func (e *Probabilistic) BrierScore() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.weight == 0 {
+		return 0.0
+	}
+	return e.brier / e.weight
+}
+
+// AUC returns the area under the ROC curve, calculated over the most recent
+// instances. Returns NaN for non-binary targets or if the window does not
+// contain both, positive and negative instances.
+func (e *Probabilistic) AUC() float64 {
+	e.mu.Lock()
+	entries := make([]scoredEntry, len(e.window))
+	copy(entries, e.window)
+	e.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].score < entries[j].score })
+
+	// Count the weight of negatives ranked below each positive,
+	// ties count half
+	var pos, neg, sum float64
+	for i := 0; i < len(entries); {
+		j := i
+		var tiePos, tieNeg float64
+		for ; j < len(entries) && entries[j].score == entries[i].score; j++ {
+			if entries[j].positive {
+				tiePos += entries[j].weight
+			} else {
+				tieNeg += entries[j].weight
+			}
+		}
+		sum += tiePos * (neg + tieNeg/2)
+		pos += tiePos
+		neg += tieNeg
+		i = j
+	}
+
+	if pos == 0 || neg == 0 {
+		return math.NaN()
+	}
+	return sum / (pos * neg)
+}
+
+// Calibration returns the calibration bins, which relate the confidence
+// of predictions to their observed accuracy
+func (e *Probabilistic) Calibration() []CalibrationBin {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bins := make([]CalibrationBin, e.conf.NumBins)
+	width := 1 / float64(e.conf.NumBins)
+	for i := range bins {
+		bins[i] = CalibrationBin{
+			Lower:  float64(i) * width,
+			Upper:  float64(i+1) * width,
+			Weight: e.binWeights[i],
+		}
+		if w := e.binWeights[i]; w > 0 {
+			bins[i].Confidence = e.binConfidence[i] / w
+			bins[i].Accuracy = e.binCorrect[i] / w
+		}
+	}
+	return bins
+}
+
+// CalibrationError returns the expected calibration error, the weighted
+// mean absolute difference between confidence and accuracy across all bins
+func (e *Probabilistic) CalibrationError() float64 {
+	sum, weight := 0.0, 0.0
+	for _, bin := range e.Calibration() {
+		sum += bin.Weight * math.Abs(bin.Confidence-bin.Accuracy)
+		weight += bin.Weight
+	}
+	if weight == 0 {
+		return 0.0
+	}
+	return sum / weight
+}
+
+// probabilities normalises prediction votes to class probabilities
+func (e *Probabilistic) probabilities(prediction core.Prediction) []float64 {
+	probs := make([]float64, e.model.Target().Len())
+
+	sum := 0.0
+	for _, pv := range prediction {
+		index := pv.Index()
+		if index < 0 || pv.Votes <= 0 {
+			continue
+		}
+		for len(probs) <= index {
+			probs = append(probs, 0)
+		}
+		probs[index] += pv.Votes
+		sum += pv.Votes
+	}
+
+	for i := range probs {
+		if sum > 0 {
+			probs[i] /= sum
+		} else {
+			probs[i] = 1 / float64(len(probs))
+		}
+	}
+	return probs
+}
@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/testdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Probabilistic", func() {
+	var subject *Probabilistic
+	model := testdata.ClassificationModel()
+
+	BeforeEach(func() {
+		subject = NewProbabilistic(model, nil)
+		subject.Record(core.MapInstance{"play": "yes"}, core.Prediction{{AttributeValue: 0, Votes: 3}, {AttributeValue: 1, Votes: 1}})
+		subject.Record(core.MapInstance{"play": "no"}, core.Prediction{{AttributeValue: 0, Votes: 1}, {AttributeValue: 1, Votes: 1}})
+		subject.Record(core.MapInstance{"play": "no"}, core.Prediction{{AttributeValue: 1, Votes: 4}})
+		subject.Record(core.MapInstance{"play": "yes"}, core.Prediction{{AttributeValue: 0, Votes: 1}, {AttributeValue: 1, Votes: 4}})
+	})
+
+	It("should calculate stats", func() {
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+		Expect(subject.LogLoss()).To(BeNumerically("~", 0.648, 0.001))
+		Expect(subject.BrierScore()).To(BeNumerically("~", 0.476, 0.001))
+		Expect(subject.AUC()).To(BeNumerically("~", 0.75, 0.001))
+		Expect(subject.CalibrationError()).To(BeNumerically("~", 0.388, 0.001))
+	})
+
+	It("should treat missing votes as uniform", func() {
+		subject = NewProbabilistic(model, nil)
+		subject.Record(core.MapInstance{"play": "no"}, nil)
+		Expect(subject.LogLoss()).To(BeNumerically("~", math.Ln2, 0.001))
+		Expect(subject.BrierScore()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(math.IsNaN(subject.AUC())).To(BeTrue())
+	})
+
+	It("should calculate ROC-AUC over a window", func() {
+		subject = NewProbabilistic(model, &ProbabilisticConfig{WindowSize: 2})
+		subject.Record(core.MapInstance{"play": "yes"}, core.Prediction{{AttributeValue: 1, Votes: 1}})
+		subject.Record(core.MapInstance{"play": "yes"}, core.Prediction{{AttributeValue: 0, Votes: 1}})
+		subject.Record(core.MapInstance{"play": "no"}, core.Prediction{{AttributeValue: 1, Votes: 1}})
+		Expect(subject.AUC()).To(Equal(1.0))
+
+		subject.Record(core.MapInstance{"play": "yes"}, core.Prediction{{AttributeValue: 0, Votes: 1}, {AttributeValue: 1, Votes: 1}})
+		subject.Record(core.MapInstance{"play": "no"}, core.Prediction{{AttributeValue: 0, Votes: 1}, {AttributeValue: 1, Votes: 1}})
+		Expect(subject.AUC()).To(Equal(0.5))
+	})
+
+	It("should bin calibrations", func() {
+		bins := subject.Calibration()
+		Expect(bins).To(HaveLen(10))
+		Expect(bins[5].Lower).To(BeNumerically("~", 0.5, 0.001))
+		Expect(bins[5].Upper).To(BeNumerically("~", 0.6, 0.001))
+		Expect(bins[5].Weight).To(Equal(1.0))
+		Expect(bins[5].Confidence).To(Equal(0.5))
+		Expect(bins[5].Accuracy).To(Equal(0.0))
+		Expect(bins[7].Confidence).To(Equal(0.75))
+		Expect(bins[7].Accuracy).To(Equal(1.0))
+		Expect(bins[9].Weight).To(Equal(1.0))
+		Expect(bins[9].Accuracy).To(Equal(1.0))
+		Expect(bins[0].Weight).To(Equal(0.0))
+	})
+
+})
@@ -5,25 +5,48 @@ import (
 	"sync"
 
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
+	"github.com/bsm/reason/internal/stats"
 )
 
+// numCoverageBins is the resolution of prediction interval coverage
+const numCoverageBins = 100
+
 // Regression is a basic regression evaluator
 type Regression struct {
 	model *core.Model
 
 	weight float64 // total weight observed
 	sum    float64 // sum of all values
+	sum2   float64 // sum of all squared values
 
 	resSum  float64 // residual sum
 	resSum2 float64 // residual sum of squares
+	totSum  float64 // total sum of absolute deviations
 	totSum2 float64 // total sum of squares
 
+	pctSum, pctWeight float64 // sum/weight of absolute percentage errors
+	symSum, symWeight float64 // sum/weight of symmetric absolute percentage errors
+
+	median, p90 *stats.Quantile // absolute error quantiles
+
+	// coverage holds the weights of central Gaussian prediction intervals
+	// which are required to cover the actual values, binned by confidence
+	coverage       [numCoverageBins]float64
+	coverageWeight float64
+
+	detector drift.Detector
+
 	mu sync.Mutex
 }
 
 // NewRegression inits a new evaluator
 func NewRegression(model *core.Model) *Regression {
-	return &Regression{model: model}
+	return &Regression{
+		model:  model,
+		median: stats.NewQuantile(0.5),
+		p90:    stats.NewQuantile(0.9),
+	}
 }
 
 // Record records a prediction
@@ -38,20 +61,52 @@ func (e *Regression) Record(inst core.Instance, prediction core.Prediction) {
 		return
 	}
 
-	weight := inst.GetInstanceWeight()
-	actual := av.Value()
-	residual := actual - pv.Value()
+	e.record(av.Value(), pv.Value(), pv.Variance, inst.GetInstanceWeight())
+}
+
+func (e *Regression) record(actual, predicted, variance, weight float64) {
+	residual := actual - predicted
+	absRes := math.Abs(residual)
 
 	e.mu.Lock()
 	if e.weight != 0 {
 		delta := actual - e.sum/e.weight
+		e.totSum += math.Abs(delta) * weight
 		e.totSum2 += delta * delta * weight
 	}
 
-	e.resSum += math.Abs(residual) * weight
+	e.resSum += absRes * weight
 	e.resSum2 += residual * residual * weight
 
+	if actual != 0 {
+		e.pctSum += absRes / math.Abs(actual) * weight
+		e.pctWeight += weight
+	}
+	if denom := math.Abs(actual) + math.Abs(predicted); denom != 0 {
+		e.symSum += 2 * absRes / denom * weight
+	}
+	e.symWeight += weight
+
+	e.median.Add(absRes)
+	e.p90.Add(absRes)
+
+	if variance > 0 {
+		// the smallest central interval which covers the actual value
+		level := math.Erf(absRes / math.Sqrt(2*variance))
+		bin := int(level * numCoverageBins)
+		if bin >= numCoverageBins {
+			bin = numCoverageBins - 1
+		}
+		e.coverage[bin] += weight
+		e.coverageWeight += weight
+	}
+
+	if e.detector != nil {
+		e.detector.Add(e.normError(absRes))
+	}
+
 	e.sum += actual * weight
+	e.sum2 += actual * actual * weight
 	e.weight += weight
 	e.mu.Unlock()
 }
@@ -120,3 +175,144 @@ func (e *Regression) R2() float64 {
 	}
 	return 0.0
 }
+
+// SetDetector sets a drift detector which is fed with the absolute
+// error of each recorded prediction, normalised into the range of [0, 1]
+// by the standard deviation of the previously observed actual values as
+// err/(err+stddev).
+func (e *Regression) SetDetector(d drift.Detector) {
+	e.mu.Lock()
+	e.detector = d
+	e.mu.Unlock()
+}
+
+// Drift returns true if a drift of the error was detected when the
+// last prediction was recorded and/or if the detector is in a warning
+// zone. Always returns false if no detector is set.
+func (e *Regression) Drift() (detected, warning bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.detector == nil {
+		return false, false
+	}
+	return e.detector.Detected(), e.detector.Warning()
+}
+
+// RAE returns the relative absolute error, the total absolute error
+// relative to the total absolute error of a (running) mean predictor
+func (e *Regression) RAE() float64 {
+	e.mu.Lock()
+	resSum := e.resSum
+	totSum := e.totSum
+	e.mu.Unlock()
+
+	if totSum > 0 {
+		return resSum / totSum
+	}
+	return 0.0
+}
+
+// RRSE returns the root relative squared error, the root of the total
+// squared error relative to the total squared error of a (running)
+// mean predictor
+func (e *Regression) RRSE() float64 {
+	e.mu.Lock()
+	resSum2 := e.resSum2
+	totSum2 := e.totSum2
+	e.mu.Unlock()
+
+	if totSum2 > 0 {
+		return math.Sqrt(resSum2 / totSum2)
+	}
+	return 0.0
+}
+
+// MAPE returns the mean absolute percentage error as a fraction.
+// Instances with actual values of 0 are ignored.
+func (e *Regression) MAPE() float64 {
+	e.mu.Lock()
+	pctSum := e.pctSum
+	pctWeight := e.pctWeight
+	e.mu.Unlock()
+
+	if pctWeight > 0 {
+		return pctSum / pctWeight
+	}
+	return 0.0
+}
+
+// SMAPE returns the symmetric mean absolute percentage error as a fraction,
+// ranging from 0 to 2
+func (e *Regression) SMAPE() float64 {
+	e.mu.Lock()
+	symSum := e.symSum
+	symWeight := e.symWeight
+	e.mu.Unlock()
+
+	if symWeight > 0 {
+		return symSum / symWeight
+	}
+	return 0.0
+}
+
+// MedianAE returns the estimated median absolute error. Please note
+// that the estimate is based on unweighted observations.
+func (e *Regression) MedianAE() float64 {
+	return e.quantile(e.median)
+}
+
+// P90AE returns the estimated 90th percentile of the absolute error.
+// Please note that the estimate is based on unweighted observations.
+func (e *Regression) P90AE() float64 {
+	return e.quantile(e.p90)
+}
+
+// Coverage returns the fraction of actual values which fall within the
+// central Gaussian prediction interval at the given confidence level
+// (e.g. 0.95), constructed from the predicted value and variance. For
+// well calibrated predictions, the coverage should be close to the
+// confidence level. Coverage has a resolution of 0.01 and predictions
+// without a variance are ignored.
+func (e *Regression) Coverage(level float64) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.coverageWeight == 0 {
+		return 0.0
+	}
+
+	covered := 0.0
+	for i, w := range e.coverage {
+		if float64(i+1)/numCoverageBins > level+1e-9 {
+			break
+		}
+		covered += w
+	}
+	return covered / e.coverageWeight
+}
+
+// normError normalises an absolute error by the standard deviation
+// of the observed actual values, see SetDetector.
+func (e *Regression) normError(err float64) float64 {
+	if e.weight > 0 {
+		mean := e.sum / e.weight
+		if stdDev := math.Sqrt(e.sum2/e.weight - mean*mean); stdDev > 0 {
+			return err / (err + stdDev)
+		}
+	}
+	if err > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (e *Regression) quantile(q *stats.Quantile) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if q.Count() == 0 {
+		return 0.0
+	}
+	return q.Value()
+}
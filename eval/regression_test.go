@@ -2,8 +2,10 @@ package eval
 
 import (
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/drift"
 	"github.com/bsm/reason/testdata"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -31,9 +33,52 @@ var _ = Describe("Regression", func() {
 		Expect(subject.MAE()).To(BeNumerically("~", 2.27, 0.01))
 		Expect(subject.RMSE()).To(BeNumerically("~", 2.64, 0.01))
 		Expect(subject.R2()).To(BeNumerically("~", 0.39, 0.01))
+		Expect(subject.RAE()).To(BeNumerically("~", 0.79, 0.01))
+		Expect(subject.RRSE()).To(BeNumerically("~", 0.78, 0.01))
+		Expect(subject.MAPE()).To(BeNumerically("~", 0.097, 0.001))
+		Expect(subject.SMAPE()).To(BeNumerically("~", 0.095, 0.001))
+		Expect(subject.MedianAE()).To(BeNumerically("~", 2.0, 0.5))
+		Expect(subject.P90AE()).To(BeNumerically(">", subject.MedianAE()))
+		Expect(subject.Coverage(0.95)).To(Equal(0.0))
 
 		subject.Record(core.MapInstance{"hours": 28, "@weight": 2.0}, core.Prediction{{AttributeValue: 28, Votes: 1}})
 		Expect(subject.R2()).To(BeNumerically("~", 0.47, 0.01))
 	})
 
+	DescribeTable("should detect drifts", func(detector drift.Detector) {
+		prediction := core.Prediction{{AttributeValue: 20, Votes: 1}}
+
+		subject = NewRegression(model)
+		subject.SetDetector(detector)
+		for i := 0; i < 1000; i++ {
+			subject.Record(core.MapInstance{"hours": 20.0 + float64(i%3)}, prediction)
+			detected, _ := subject.Drift()
+			Expect(detected).To(BeFalse())
+		}
+
+		detected := false
+		for i := 0; i < 100 && !detected; i++ {
+			subject.Record(core.MapInstance{"hours": 25.0}, prediction)
+			detected, _ = subject.Drift()
+		}
+		Expect(detected).To(BeTrue())
+	},
+		Entry("Page-Hinkley", drift.NewPageHinkley(0, 10)),
+		Entry("ADWIN", drift.NewADWIN(0)),
+		Entry("DDM", drift.NewDDM(0, 0)),
+	)
+
+	It("should calculate prediction interval coverage", func() {
+		subject = NewRegression(model)
+		subject.Record(core.MapInstance{"hours": 20.5}, core.Prediction{{AttributeValue: 20, Votes: 1, Variance: 1}})
+		subject.Record(core.MapInstance{"hours": 21.5}, core.Prediction{{AttributeValue: 20, Votes: 1, Variance: 1}})
+		subject.Record(core.MapInstance{"hours": 17.5}, core.Prediction{{AttributeValue: 20, Votes: 1, Variance: 1}})
+		subject.Record(core.MapInstance{"hours": 20.1}, core.Prediction{{AttributeValue: 20, Votes: 1, Variance: 1}})
+		subject.Record(core.MapInstance{"hours": 25.0}, core.Prediction{{AttributeValue: 20, Votes: 1}})
+
+		Expect(subject.Coverage(0.5)).To(Equal(0.5))
+		Expect(subject.Coverage(0.95)).To(Equal(0.75))
+		Expect(subject.Coverage(0.99)).To(Equal(1.0))
+	})
+
 })
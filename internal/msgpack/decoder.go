@@ -43,6 +43,29 @@ func (d *Decoder) Decode(vv ...interface{}) error {
 	return nil
 }
 
+// DecodeVersion reads a format version marker, written by
+// Encoder.EncodeVersion. It returns 0 without consuming any input
+// if the next value is not a version marker, i.e. if it was encoded
+// before the format was versioned.
+func (d *Decoder) DecodeVersion() (uint8, error) {
+	b, err := d.r.Peek(3)
+	if err == io.EOF {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if b[0] != mfixext1 || int8(b[1]) != versionExtType {
+		return 0, nil
+	}
+
+	v := b[2]
+	if _, err := d.r.Discard(3); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
 // Decode decodes a value
 func (d *Decoder) DecodeValue(rv reflect.Value) error {
 	if rv.Kind() != reflect.Ptr {
@@ -146,6 +169,13 @@ func (d *Decoder) decodeValue(rv reflect.Value, customType reflect.Type) error {
 			elem.Set(cp.Elem())
 			return nil
 		}
+
+		if isNil, err := d.isNil(); err != nil {
+			return err
+		} else if isNil {
+			elem.Set(reflect.Zero(elem.Type()))
+			return nil
+		}
 	}
 
 	return errTypeNotSupported(elem.Type())
@@ -58,6 +58,7 @@ var _ = Describe("Decoder", func() {
 		Expect(enc.Encode(([]int)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Encode((mockSetType)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Encode((*mockSliceType)(nil))).NotTo(HaveOccurred())
+		Expect(enc.Encode((mockInterface)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Close()).NotTo(HaveOccurred())
 
 		dec := NewDecoder(buf)
@@ -72,6 +73,10 @@ var _ = Describe("Decoder", func() {
 		var msl *mockSliceType
 		Expect(dec.Decode(&msl)).NotTo(HaveOccurred())
 		Expect(msl).To(BeNil())
+
+		var mif mockInterface = &mockSliceType{}
+		Expect(dec.Decode(&mif)).NotTo(HaveOccurred())
+		Expect(mif).To(BeNil())
 	})
 
 	It("should decode custom types", func() {
@@ -117,4 +122,25 @@ var _ = Describe("Decoder", func() {
 		}))
 	})
 
+	It("should decode versions", func() {
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		Expect(enc.EncodeVersion(2)).NotTo(HaveOccurred())
+		Expect(enc.Encode(7, mockNone{})).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		dec := NewDecoder(buf)
+		Expect(dec.DecodeVersion()).To(Equal(uint8(2)))
+		Expect(dec.DecodeVersion()).To(Equal(uint8(0)))
+
+		var n int
+		Expect(dec.Decode(&n)).NotTo(HaveOccurred())
+		Expect(n).To(Equal(7))
+		Expect(dec.DecodeVersion()).To(Equal(uint8(0)))
+
+		var mn mockNone
+		Expect(dec.Decode(&mn)).NotTo(HaveOccurred())
+		Expect(dec.DecodeVersion()).To(Equal(uint8(0)))
+	})
+
 })
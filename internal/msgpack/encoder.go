@@ -34,8 +34,23 @@ func (e *Encoder) Encode(vv ...interface{}) error {
 	return nil
 }
 
+// EncodeVersion writes a format version marker. Markers allow types to
+// evolve their encodings, see Decoder.DecodeVersion.
+func (e *Encoder) EncodeVersion(v uint8) error {
+	if err := e.w.WriteByte(byte(mfixext1)); err != nil {
+		return err
+	}
+	if err := e.w.WriteByte(byte(versionExtType)); err != nil {
+		return err
+	}
+	return e.w.WriteByte(v)
+}
+
 // EncodeValue writes a value
 func (e *Encoder) EncodeValue(v reflect.Value) error {
+	if !v.IsValid() {
+		return e.writeNil()
+	}
 	if v.Kind() == reflect.Interface {
 		return e.EncodeValue(v.Elem())
 	}
@@ -40,12 +40,14 @@ var _ = Describe("Encode", func() {
 		Expect(enc.Encode(([]int)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Encode((mockSetType)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Encode((*mockSliceType)(nil))).NotTo(HaveOccurred())
+		Expect(enc.Encode((mockInterface)(nil))).NotTo(HaveOccurred())
 		Expect(enc.Close()).NotTo(HaveOccurred())
 
 		exp := []byte{
 			mnil,
 			mfixext2, 8, 0x0, 112, mnil,
 			mfixext2, 8, 0x0, 111, mnil,
+			mnil,
 		}
 		Expect(buf.Bytes()).To(Equal(exp), "expected: %#v\ngot:      %#v", exp, buf.Bytes())
 	})
@@ -76,4 +78,15 @@ var _ = Describe("Encode", func() {
 		Expect(buf.Bytes()).To(Equal(exp), "expected: %#v\ngot:      %#v", exp, buf.Bytes())
 	})
 
+	It("should encode versions", func() {
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		Expect(enc.EncodeVersion(2)).NotTo(HaveOccurred())
+		Expect(enc.Encode(7)).NotTo(HaveOccurred())
+		Expect(enc.Close()).NotTo(HaveOccurred())
+
+		exp := []byte{mfixext1, 9, 2, 7}
+		Expect(buf.Bytes()).To(Equal(exp), "expected: %#v\ngot:      %#v", exp, buf.Bytes())
+	})
+
 })
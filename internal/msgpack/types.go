@@ -6,7 +6,10 @@ import (
 	"sync"
 )
 
-const customExtType int8 = 8
+const (
+	customExtType  int8 = 8
+	versionExtType int8 = 9
+)
 
 var (
 	registerLock       sync.RWMutex
@@ -45,6 +45,24 @@ func (k *Kappa) Value() float64 {
 	return (obs - exp) / (sum - exp)
 }
 
+// Matrix returns a copy of the recorded weights as a square
+// matrix, indexed by actual and expected value
+func (k *Kappa) Matrix() [][]float64 {
+	n := k.ncols
+	if len(k.m) > n {
+		n = len(k.m)
+	}
+
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		if i < len(k.m) {
+			copy(m[i], k.m[i])
+		}
+	}
+	return m
+}
+
 func (k *Kappa) grow(n int) {
 	if d := n - len(k.m); d > 0 {
 		k.m = append(k.m, make([][]float64, d)...)
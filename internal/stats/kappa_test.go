@@ -38,6 +38,17 @@ var _ = Describe("Kappa", func() {
 		}))
 	})
 
+	It("should return the matrix", func() {
+		m := subject.Matrix()
+		Expect(m).To(Equal([][]float64{
+			{22, 9},
+			{7, 13},
+		}))
+
+		m[0][0] = 1
+		Expect(subject.Value()).To(BeNumerically("~", 0.353, 0.001))
+	})
+
 	It("should calculate value", func() {
 		Expect(subject.Value()).To(BeNumerically("~", 0.353, 0.001))
 	})
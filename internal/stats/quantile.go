@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Quantile is a streaming quantile estimator, which uses constant memory.
+// Values are unweighted. See "The P² algorithm for dynamic calculation of
+// quantiles and histograms without storing observations" by Raj Jain and
+// Imrich Chlamtac (1985).
+type Quantile struct {
+	p float64
+	n int
+
+	heights   [5]float64 // marker heights
+	positions [5]float64 // actual marker positions
+	desired   [5]float64 // desired marker positions
+	incs      [5]float64 // increments of desired positions
+}
+
+// NewQuantile inits a new estimator for the p-quantile,
+// where 0 < p < 1.
+func NewQuantile(p float64) *Quantile {
+	return &Quantile{
+		p:       p,
+		desired: [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		incs:    [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Count returns the number of observed values
+func (q *Quantile) Count() int { return q.n }
+
+// Value returns the estimated quantile. Returns NaN if no
+// values have been observed.
+func (q *Quantile) Value() float64 {
+	if q.n == 0 {
+		return math.NaN()
+	}
+	if q.n < 5 {
+		vals := make([]float64, q.n)
+		copy(vals, q.heights[:q.n])
+		sort.Float64s(vals)
+		return vals[int(math.Round(q.p*float64(q.n-1)))]
+	}
+	return q.heights[2]
+}
+
+// Add adds a value
+func (q *Quantile) Add(v float64) {
+	if q.n < 5 {
+		q.heights[q.n] = v
+		if q.n++; q.n == 5 {
+			sort.Float64s(q.heights[:])
+			q.positions = [5]float64{1, 2, 3, 4, 5}
+		}
+		return
+	}
+	q.n++
+
+	// Find the cell k and adjust the extreme markers
+	var k int
+	switch {
+	case v < q.heights[0]:
+		q.heights[0] = v
+		k = 0
+	case v >= q.heights[4]:
+		q.heights[4] = v
+		k = 3
+	default:
+		for k = 0; k < 3 && v >= q.heights[k+1]; k++ {
+		}
+	}
+
+	// Increment positions
+	for i := k + 1; i < 5; i++ {
+		q.positions[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.incs[i]
+	}
+
+	// Adjust the heights of the middle markers
+	for i := 1; i < 4; i++ {
+		d := q.desired[i] - q.positions[i]
+		if (d >= 1 && q.positions[i+1]-q.positions[i] > 1) || (d <= -1 && q.positions[i-1]-q.positions[i] < -1) {
+			s := 1
+			if d < 0 {
+				s = -1
+			}
+
+			if h := q.parabolic(i, float64(s)); q.heights[i-1] < h && h < q.heights[i+1] {
+				q.heights[i] = h
+			} else {
+				q.heights[i] += float64(s) * (q.heights[i+s] - q.heights[i]) / (q.positions[i+s] - q.positions[i])
+			}
+			q.positions[i] += float64(s)
+		}
+	}
+}
+
+func (q *Quantile) parabolic(i int, s float64) float64 {
+	h, n := q.heights, q.positions
+	return h[i] + s/(n[i+1]-n[i-1])*((n[i]-n[i-1]+s)*(h[i+1]-h[i])/(n[i+1]-n[i])+(n[i+1]-n[i]-s)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quantile", func() {
+
+	It("should estimate small samples exactly", func() {
+		subject := NewQuantile(0.5)
+		Expect(math.IsNaN(subject.Value())).To(BeTrue())
+
+		subject.Add(3)
+		subject.Add(1)
+		subject.Add(2)
+		Expect(subject.Count()).To(Equal(3))
+		Expect(subject.Value()).To(Equal(2.0))
+	})
+
+	It("should estimate quantiles", func() {
+		rnd := rand.New(rand.NewSource(1))
+		median, p90 := NewQuantile(0.5), NewQuantile(0.9)
+		for i := 0; i < 10000; i++ {
+			v := rnd.Float64() * 100
+			median.Add(v)
+			p90.Add(v)
+		}
+		Expect(median.Count()).To(Equal(10000))
+		Expect(median.Value()).To(BeNumerically("~", 50, 1))
+		Expect(p90.Value()).To(BeNumerically("~", 90, 1))
+
+		normal := NewQuantile(0.9)
+		for i := 0; i < 10000; i++ {
+			normal.Add(rnd.NormFloat64())
+		}
+		Expect(normal.Value()).To(BeNumerically("~", 1.28, 0.05))
+	})
+
+})
@@ -1,12 +1,8 @@
 package testdata
 
 import (
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
 	"github.com/bsm/reason/core"
+	"github.com/bsm/reason/dataset"
 )
 
 func BigClassificationModel() *core.Model {
@@ -36,88 +32,8 @@ func BigRegressionModel() *core.Model {
 	)
 }
 
-type BigDataStream struct {
-	file  *os.File
-	recs  *csv.Reader
-	model *core.Model
-
-	inst core.MapInstance
-	err  error
-}
-
-func Open(fname string, model *core.Model) (*BigDataStream, error) {
-	f, err := os.Open(fname)
-	if err != nil {
-		return nil, err
-	}
-
-	recs := csv.NewReader(f)
-	recs.FieldsPerRecord = model.NumPredictors() + 1
-
-	return &BigDataStream{
-		file:  f,
-		recs:  recs,
-		model: model,
-		inst:  make(core.MapInstance, recs.FieldsPerRecord),
-	}, nil
-}
-
-func (s *BigDataStream) Next() bool {
-	if s.err != nil {
-		return false
-	}
-
-	fields, err := s.recs.Read()
-	if err != nil {
-		s.err = err
-		return false
-	}
-
-	// init instance
-	s.inst = make(core.MapInstance, len(s.inst))
-
-	// read predictors
-	for i, attr := range s.model.Predictors() {
-		str := fields[i]
-		if str == "?" {
-			continue
-		} else if attr.IsNominal() {
-			s.inst[attr.Name] = str
-		} else if s.inst[attr.Name], err = strconv.ParseFloat(str, 64); err != nil {
-			s.err = err
-			return false
-		}
-	}
-
-	// read target
-	str := fields[len(fields)-1]
-	if target := s.model.Target(); target.IsNominal() {
-		s.inst[target.Name] = str
-	} else if s.inst[target.Name], err = strconv.ParseFloat(str, 64); err != nil {
-		s.err = err
-		return false
-	}
-
-	return true
-}
-
-func (s *BigDataStream) ReadN(n int) ([]core.Instance, error) {
-	res := make([]core.Instance, 0, n)
-	for s.Next() {
-		res = append(res, s.Instance())
-		if len(res) == n {
-			break
-		}
-	}
-	return res, s.Err()
-}
-
-func (s *BigDataStream) Err() error {
-	if s.err == io.EOF {
-		return nil
-	}
-	return s.err
+// Open opens a big data CSV file, with columns in the order
+// of the model's predictors followed by the target
+func Open(fname string, model *core.Model) (*dataset.CSVReader, error) {
+	return dataset.OpenCSV(fname, model, nil)
 }
-
-func (s *BigDataStream) Instance() core.Instance { return s.inst }
-func (s *BigDataStream) Close() error            { return s.file.Close() }
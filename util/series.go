@@ -26,6 +26,21 @@ func (s *NumSeries) Append(value, weight float64) {
 	s.sumSquares += wv * value
 }
 
+// Merge merges another series into this one
+func (s *NumSeries) Merge(o *NumSeries) {
+	s.weight += o.weight
+	s.sum += o.sum
+	s.sumSquares += o.sumSquares
+}
+
+// Sub removes the values of another series from this one,
+// the inverse of Merge
+func (s *NumSeries) Sub(o *NumSeries) {
+	s.weight -= o.weight
+	s.sum -= o.sum
+	s.sumSquares -= o.sumSquares
+}
+
 // TotalWeight returns total observed weight of that series, usually equavalent
 // to the count of observations
 func (s *NumSeries) TotalWeight() float64 { return s.weight }
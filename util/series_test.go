@@ -27,6 +27,23 @@ var _ = Describe("NumSeries", func() {
 		Expect(new(NumSeries).TotalWeight()).To(Equal(0.0))
 	})
 
+	It("should merge", func() {
+		other := new(NumSeries)
+		other.Append(2.2, 2)
+		subject.Merge(other)
+		Expect(subject.TotalWeight()).To(Equal(11.0))
+		Expect(subject.Sum()).To(BeNumerically("~", 53.9, 0.001))
+	})
+
+	It("should sub", func() {
+		other := new(NumSeries)
+		other.Append(1.1, 1)
+		other.Append(2.2, 1)
+		subject.Sub(other)
+		Expect(subject.TotalWeight()).To(Equal(7.0))
+		Expect(subject.Sum()).To(BeNumerically("~", 46.2, 0.001))
+	})
+
 	It("should return value sum", func() {
 		Expect(subject.Sum()).To(Equal(49.5))
 		Expect(new(NumSeries).Sum()).To(Equal(0.0))